@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTrackAnimationFrameFiresOnce verifies trackAnimationFrame only
+// enqueues a group once all 24 distinct hours have been recorded, and that
+// it treats frames reported from different calls (standing in for the
+// enqueue loop's "already on disk" path and the collector's "just
+// rendered" path) the same way.
+func TestTrackAnimationFrameFiresOnce(t *testing.T) {
+	seen := map[string]map[string]bool{}
+	animateCh := make(chan animGroup, 1)
+
+	for h := 0; h < 23; h++ {
+		trackAnimationFrame(seen, task{MapType: "MUF", Year: "2026", Month: "Jul", Freq: "14", Hour: fmt.Sprintf("%02d", h)}, animateCh)
+	}
+	select {
+	case g := <-animateCh:
+		t.Fatalf("group fired early with only 23/24 hours seen: %+v", g)
+	default:
+	}
+
+	trackAnimationFrame(seen, task{MapType: "MUF", Year: "2026", Month: "Jul", Freq: "14", Hour: "23", OutDir: "/out"}, animateCh)
+	select {
+	case g := <-animateCh:
+		if g.MapType != "MUF" || g.Year != "2026" || g.Month != "Jul" || g.Freq != "14" || g.OutDir != "/out" {
+			t.Errorf("unexpected group: %+v", g)
+		}
+	default:
+		t.Fatal("group did not fire once all 24 hours were seen")
+	}
+
+	if _, ok := seen["MUF/2026/Jul/14"]; ok {
+		t.Error("completed group was not removed from seen")
+	}
+}
+
+// TestTrackAnimationFrameCountsPreExistingFrames covers the chunk1-3 bug:
+// frames the enqueue loop skips because they already exist on disk must
+// still count toward the group, the same as frames the collector reports
+// after rendering them this run.
+func TestTrackAnimationFrameCountsPreExistingFrames(t *testing.T) {
+	seen := map[string]map[string]bool{}
+	animateCh := make(chan animGroup, 1)
+
+	// Hours 0-11 are "already on disk" (enqueue-loop path); 12-23 are
+	// "rendered this run" (collector path). Either caller uses the same
+	// seen map and channel.
+	for h := 0; h < 12; h++ {
+		trackAnimationFrame(seen, task{MapType: "REL", Year: "2026", Month: "Jun", Freq: "21", Hour: fmt.Sprintf("%02d", h)}, animateCh)
+	}
+	for h := 12; h < 24; h++ {
+		trackAnimationFrame(seen, task{MapType: "REL", Year: "2026", Month: "Jun", Freq: "21", Hour: fmt.Sprintf("%02d", h)}, animateCh)
+	}
+
+	select {
+	case <-animateCh:
+	default:
+		t.Fatal("group of all-pre-existing-plus-rendered frames never fired")
+	}
+}
+
+// TestBuildAPNG checks that buildAPNG's hand-rolled chunk writer produces a
+// file any PNG decoder can still open (an APNG's fcTL/fdAT chunks are
+// ancillary, so image/png.Decode should just read frame 0's IDAT and
+// ignore the rest), and that it actually contains one acTL and one fcTL
+// per frame, plus an fdAT for every frame after the first.
+func TestBuildAPNG(t *testing.T) {
+	dir := t.TempDir()
+	frames := make([]string, 3)
+	for i := range frames {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		img.Set(0, 0, color.RGBA{R: uint8(i * 50), A: 255})
+		p := filepath.Join(dir, fmt.Sprintf("frame%d.png", i))
+		f, err := os.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(f, img); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		frames[i] = p
+	}
+
+	out := filepath.Join(dir, "out.apng")
+	if err := buildAPNG(frames, out); err != nil {
+		t.Fatalf("buildAPNG: %v", err)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(raw)); err != nil {
+		t.Errorf("a plain PNG decoder could not read the APNG's first frame: %v", err)
+	}
+
+	chunks, err := readPNGChunks(raw)
+	if err != nil {
+		t.Fatalf("readPNGChunks: %v", err)
+	}
+	var acTL, fcTL, fdAT int
+	for _, c := range chunks {
+		switch c.typ {
+		case "acTL":
+			acTL++
+		case "fcTL":
+			fcTL++
+		case "fdAT":
+			fdAT++
+		}
+	}
+	if acTL != 1 {
+		t.Errorf("got %d acTL chunks, want 1", acTL)
+	}
+	if fcTL != len(frames) {
+		t.Errorf("got %d fcTL chunks, want %d (one per frame)", fcTL, len(frames))
+	}
+	if fdAT < len(frames)-1 {
+		t.Errorf("got %d fdAT chunks, want at least %d (one per frame after the first)", fdAT, len(frames)-1)
+	}
+}
@@ -0,0 +1,986 @@
+/*
+Copyright 2025 Jari Perkiömäki OH6BG
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+/*
+Usage examples:
+
+go run ./cmd/plot_maps
+go run ./cmd/plot_maps --root "/home/user/predictions/24985382" --maps "ALL" --workers 4
+go run ./cmd/plot_maps --root "/home/user/predictions/24985382" --maps "SDBW,SNR50" --progress=false
+
+Compile the code to an executable:
+
+go build -o plotmaps.exe ./cmd/plot_maps
+./plotmaps.exe --root "/home/user/predictions/24985382" --maps "SDBW,SNR50"
+
+The progress counter is ON by default. Use Ctrl+C to cancel plotting.
+
+Driving this from a CI job matrix, a la Go's own test/run.go shards:
+
+./plotmaps.exe --root ROOT --maps ALL --shards 4 --shard 0 --summary
+./plotmaps.exe --root ROOT --maps ALL --run "SNR50/2025/(01|02)/(0[0-9])UT-14MHz" --keep-going=false
+
+--engine=native renders PNGs in-process via the plotter package instead of
+shelling out to pythonExe+plotScriptPath for every task:
+
+./plotmaps.exe --root ROOT --maps ALL --engine native --workers 8
+
+--animate=gif,apng,mp4,webp stitches each (MapType,Year,Month,Freq) group's
+24 hourly PNGs into a looping animation once all 24 exist, written next to
+them (e.g. SNR50/2025/03/14MHz.mp4). gif and apng are built in pure Go
+(image/gif plus a hand-rolled APNG chunk writer, since neither the standard
+library nor golang.org/x/image ships one); mp4 always shells out to
+--ffmpeg for H.264, and webp does too, since no pure-Go WebP encoder exists
+either (x/image/webp only decodes):
+
+./plotmaps.exe --root ROOT --maps ALL --animate gif,apng
+./plotmaps.exe --root ROOT --maps ALL --animate mp4,webp --ffmpeg /usr/bin/ffmpeg
+
+--serve :8080 runs a long-lived server instead of a one-shot batch: it
+polls ROOT every few seconds for new/changed .voa and .vg* files (e.g.
+produced by a voacapl job still running), enqueues plot tasks as they
+appear, and serves an HTTP API (see serve.go for the endpoint list). Progress
+and per-task retry state survive restarts via a .plotmaps-state.json
+checkpoint written inside ROOT. --animate is not supported in this mode.
+
+./plotmaps.exe --root ROOT --maps ALL --serve :8080 --engine native
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/OH6BG/VOACAP_MAPS/plotter"
+	"github.com/OH6BG/VOACAP_MAPS/voafile"
+)
+
+// Hardcoded tool paths (edit to match your system)
+const (
+	pythonExe      = `/usr/bin/python`
+	plotScriptPath = `/home/user/pythonprop/src/pythonprop/voaAreaPlot.py`
+	perPlotTimeout = 60 * time.Second // default timeout; not prompted
+)
+
+type mapCfg struct {
+	DFlag string // -d flag to voaAreaPlot.py
+	Dir   string // subdirectory name
+}
+
+var (
+	// Map type configuration (extendable)
+	mapTypes = map[string]mapCfg{
+		"MUF":   {DFlag: "1", Dir: "MUF"},
+		"REL":   {DFlag: "2", Dir: "REL"},
+		"SNR50": {DFlag: "3", Dir: "SNR50"},
+		"SNR90": {DFlag: "4", Dir: "SNR90"},
+		"SDBW":  {DFlag: "5", Dir: "SDBW"},
+	}
+)
+
+// CLI flags
+var (
+	rootPath  = flag.String("root", "", "Root path to VOACAP outputs (contains year/month subfolders with .voa/.vg* files)")
+	mapsFlag  = flag.String("maps", "", "Comma-separated map types: MUF,REL,SNR50,SNR90,SDBW (or ALL)")
+	workers   = flag.Int("workers", max(1, runtime.NumCPU()), "Max parallel plots")
+	progress  = flag.Bool("progress", true, "Show live progress")
+	shardFlag = flag.Int("shard", 0, "This process's shard index (0-based, requires --shards > 1)")
+	shardsN   = flag.Int("shards", 1, "Total number of shards; each task runs on exactly one shard")
+	runFlag   = flag.String("run", "", "Regexp matched against MapType/Year/Month/HHUT-FFMHz; only matching tasks run")
+	summary   = flag.Bool("summary", false, "Print a per-map-type outcome summary when done")
+	keepGoing = flag.Bool("keep-going", true, "Keep plotting other tasks after a failure; false cancels the run on the first failure")
+	verbose   = flag.Bool("verbose", false, "Log each successfully completed plot")
+	engine    = flag.String("engine", "python", "Rendering engine: python (shell out to voaAreaPlot.py) or native (render in-process with the plotter package)")
+	animate   = flag.String("animate", "", "Comma-separated animation formats to build per (MapType,Year,Month,Freq) group once all 24 hourly frames exist: gif,apng,mp4,webp (gif and apng are pure Go; mp4 and webp require --ffmpeg)")
+	ffmpegBin = flag.String("ffmpeg", "ffmpeg", "Path to the ffmpeg binary, used by --animate=mp4 and --animate=webp (not needed for gif or apng)")
+	serveAddr = flag.String("serve", "", "Run a long-lived server at this address (e.g. :8080) instead of a one-shot batch: polls --root for new .voa/.vg* files and exposes an HTTP API (see package doc)")
+)
+
+func main() {
+	flag.Parse()
+
+	// Interactive prompts if required fields are missing
+	if strings.TrimSpace(*rootPath) == "" {
+		*rootPath = askUntilValidDir("Enter ROOT directory (contains year/month subfolders): ")
+	}
+	if strings.TrimSpace(*mapsFlag) == "" {
+		fmt.Println("Select maps to plot (comma-separated) from: MUF, REL, SNR50, SNR90, SDBW or 'ALL'")
+		*mapsFlag = ask("Maps: ")
+	}
+
+	if *engine != "python" && *engine != "native" {
+		fmt.Fprintf(os.Stderr, "Error: --engine must be python or native (got %q)\n", *engine)
+		os.Exit(2)
+	}
+
+	// Validate hardcoded tool paths (native engine needs neither)
+	if *engine == "python" {
+		if st, err := os.Stat(pythonExe); err != nil || st.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: python interpreter not found: %s\n", pythonExe)
+			os.Exit(2)
+		}
+		if st, err := os.Stat(plotScriptPath); err != nil || st.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: plot script not found: %s\n", plotScriptPath)
+			os.Exit(2)
+		}
+	}
+
+	root := filepath.Clean(*rootPath)
+
+	// Normalize/validate selected maps
+	selected, err := parseSelectedMaps(*mapsFlag)
+	if err != nil || len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *shardsN < 1 || *shardFlag < 0 || *shardFlag >= *shardsN {
+		fmt.Fprintf(os.Stderr, "Error: --shard must be in [0, --shards) (got shard=%d, shards=%d)\n", *shardFlag, *shardsN)
+		os.Exit(2)
+	}
+	var runRe *regexp.Regexp
+	if strings.TrimSpace(*runFlag) != "" {
+		runRe, err = regexp.Compile(*runFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --run regexp: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	var animateFormats []string
+	needFFmpeg := false
+	for _, f := range strings.Split(*animate, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if f != "gif" && f != "apng" && f != "mp4" && f != "webp" {
+			fmt.Fprintf(os.Stderr, "Error: unknown --animate format %q (want gif, apng, mp4, webp)\n", f)
+			os.Exit(2)
+		}
+		animateFormats = append(animateFormats, f)
+		if f == "mp4" || f == "webp" {
+			needFFmpeg = true
+		}
+	}
+	if needFFmpeg {
+		if _, err := exec.LookPath(*ffmpegBin); err != nil {
+			if st, serr := os.Stat(*ffmpegBin); serr != nil || st.IsDir() {
+				fmt.Fprintf(os.Stderr, "Error: ffmpeg not found at %q, required for --animate=mp4,webp: %v\n", *ffmpegBin, err)
+				os.Exit(2)
+			}
+		}
+	}
+
+	if strings.TrimSpace(*serveAddr) != "" {
+		if len(animateFormats) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: --animate is not supported together with --serve")
+			os.Exit(2)
+		}
+		if err := runServe(root, selected, *engine, *serveAddr, *workers, *verbose); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Discover newest .voa per directory, with their sibling .vgN files,
+	// once via the shared voafile parser.
+	voaSets, err := voafile.Scan(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning .voa files: %v\n", err)
+		os.Exit(1)
+	}
+	if len(voaSets) == 0 {
+		fmt.Fprintf(os.Stderr, "No .voa files found under %s\n", root)
+		os.Exit(1)
+	}
+
+	start := time.Now()
+	fmt.Printf("Plotting maps (%s) with %d workers...\n", strings.Join(selected, ","), *workers)
+
+	// Shared context and Ctrl+C handling
+	rootCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// workCtx additionally cancels on the first failure when --keep-going=false
+	workCtx, cancelWork := context.WithCancel(rootCtx)
+	defer cancelWork()
+
+	// Build tasks = combinations of (.voa, matching .vg*, selected map types)
+	tasks := make(chan task, 1024)
+	var wg sync.WaitGroup
+
+	// Result tracking (always consumed, regardless of --progress/--summary, so workers never block on doneCh)
+	doneCh := make(chan taskResult, 4096)
+	var printerWG sync.WaitGroup
+	var completed int
+	var total int
+	stats := map[string]*mapTypeStats{}
+
+	// Start worker pool
+	nw := max(1, *workers)
+	for i := 0; i < nw; i++ {
+		wg.Add(1)
+		go worker(workCtx, tasks, &wg, pythonExe, plotScriptPath, perPlotTimeout, doneCh, *verbose, *keepGoing, cancelWork, *engine)
+	}
+
+	// Animation pool: consumes (MapType,Year,Month,Freq) groups as soon as
+	// all 24 hourly frames for that group exist, so animating overlaps
+	// plotting instead of running as a separate pass afterward. groups is
+	// touched by the enqueue loop (frames already on disk) and then by the
+	// result-collector goroutine, which starts only after that loop ends,
+	// so it needs no locking.
+	groups := map[string]map[string]bool{}
+	var animateCh chan animGroup
+	var animWG sync.WaitGroup
+	if len(animateFormats) > 0 {
+		animateCh = make(chan animGroup, 4096)
+		na := max(1, nw/2)
+		for i := 0; i < na; i++ {
+			animWG.Add(1)
+			go animateWorker(workCtx, animateCh, &animWG, animateFormats, *ffmpegBin, *verbose)
+		}
+	}
+
+	// Enqueue tasks
+	enqCount := 0
+	skippedShard := 0
+	skippedFilter := 0
+enqueue:
+	for _, set := range voaSets {
+		select {
+		case <-workCtx.Done():
+			break enqueue
+		default:
+		}
+		if len(set.VGs) == 0 {
+			fmt.Fprintf(os.Stderr, "Warn: no matching VG files near %s\n", set.Path)
+			continue
+		}
+
+		for _, vg := range set.VGs {
+			select {
+			case <-workCtx.Done():
+				break enqueue
+			default:
+			}
+			hh := fmt.Sprintf("%02d", vg.Hour)
+			ff := fmt.Sprintf("%02.0f", vg.FreqMHz)
+			for _, m := range selected {
+				cfg := mapTypes[m]
+				// Output under ROOT/<TYPE>/<Year>/<Month>/
+				outDir := filepath.Join(root, cfg.Dir, set.Year, set.Month)
+				outFile := filepath.Join(outDir, fmt.Sprintf("%sUT-%sMHz.png", hh, ff))
+				t := task{
+					VOA:     set.Path,
+					VG:      vg.Path,
+					VGNum:   strconv.Itoa(vg.Index),
+					MapType: m,
+					DFlag:   cfg.DFlag,
+					OutDir:  outDir,
+					OutFile: outFile,
+					Year:    set.Year,
+					Month:   set.Month,
+					Hour:    hh,
+					Freq:    ff,
+				}
+				// Skip enqueue if the target already exists, but still
+				// count the frame toward --animate group completion:
+				// otherwise a rerun over a ROOT with hourly PNGs already
+				// rendered from a prior run never fires animation for
+				// those groups even though all 24 frames are on disk.
+				if _, err := os.Stat(outFile); err == nil {
+					if animateFormats != nil {
+						trackAnimationFrame(groups, t, animateCh)
+					}
+					continue
+				}
+				if *shardsN > 1 && shardOf(taskKey(t), *shardsN) != *shardFlag {
+					skippedShard++
+					continue
+				}
+				if runRe != nil && !runRe.MatchString(taskKey(t)) {
+					skippedFilter++
+					continue
+				}
+				select {
+				case tasks <- t:
+					enqCount++
+				case <-workCtx.Done():
+					break enqueue
+				}
+			}
+		}
+	}
+
+	close(tasks)
+	total = enqCount
+	if *shardsN > 1 || runRe != nil {
+		fmt.Printf("Selected %d tasks (skipped %d by shard, %d by --run filter)\n", enqCount, skippedShard, skippedFilter)
+	}
+
+	// Result collector: always drains doneCh, optionally prints a ticking progress line
+	printerWG.Add(1)
+	go func() {
+		defer printerWG.Done()
+		var ticker *time.Ticker
+		var tickC <-chan time.Time
+		if *progress {
+			ticker = time.NewTicker(500 * time.Millisecond)
+			tickC = ticker.C
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-tickC:
+				fmt.Printf("\rProgress: %d/%d", completed, total)
+			case r, ok := <-doneCh:
+				if !ok {
+					if *progress {
+						fmt.Printf("\rProgress: %d/%d\n", completed, total)
+					}
+					return
+				}
+				completed++
+				st, ok := stats[r.Task.MapType]
+				if !ok {
+					st = &mapTypeStats{}
+					stats[r.Task.MapType] = st
+				}
+				switch r.Outcome {
+				case outcomeSucceeded:
+					st.Succeeded++
+				case outcomeSkipped:
+					st.Skipped++
+				case outcomeFailed:
+					st.Failed++
+				case outcomeTimedOut:
+					st.TimedOut++
+				}
+				if animateFormats != nil && (r.Outcome == outcomeSucceeded || r.Outcome == outcomeSkipped) {
+					trackAnimationFrame(groups, r.Task, animateCh)
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(doneCh)
+	printerWG.Wait()
+	if animateCh != nil {
+		close(animateCh)
+		animWG.Wait()
+	}
+
+	if err := workCtx.Err(); err != nil && err != context.Canceled {
+		fmt.Fprintf(os.Stderr, "Stopped: %v\n", err)
+	} else if workCtx.Err() == context.Canceled && rootCtx.Err() == nil {
+		fmt.Fprintln(os.Stderr, "Stopped: a task failed and --keep-going=false")
+	}
+
+	if *summary {
+		printSummary(stats)
+	}
+	fmt.Printf("Done. Plots attempted: %d in %s\n", enqCount, time.Since(start).Truncate(time.Millisecond))
+}
+
+func printSummary(stats map[string]*mapTypeStats) {
+	types := make([]string, 0, len(stats))
+	for k := range stats {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+	fmt.Println("Summary by map type:")
+	for _, m := range types {
+		st := stats[m]
+		fmt.Printf("  %-6s succeeded=%-4d skipped=%-4d failed=%-4d timed-out=%-4d\n",
+			m, st.Succeeded, st.Skipped, st.Failed, st.TimedOut)
+	}
+}
+
+// ----- Animation -----
+
+// animGroup is the 24 hourly PNGs for one (MapType, Year, Month, Freq)
+// combination, once every hour 00-23 has a frame on disk.
+type animGroup struct {
+	MapType, Year, Month, Freq, OutDir string
+}
+
+// trackAnimationFrame records that t's hourly frame now exists on disk
+// (either this run rendered it or it already existed) and, once all 24
+// hours of its group are accounted for, enqueues the group for animation.
+// Called from the enqueue loop (for frames already on disk before this run)
+// and from the single result-collector goroutine (for frames this run
+// rendered); those two callers never run concurrently with each other, so
+// the seen map below needs no locking.
+func trackAnimationFrame(seen map[string]map[string]bool, t task, animateCh chan<- animGroup) {
+	key := fmt.Sprintf("%s/%s/%s/%s", t.MapType, t.Year, t.Month, t.Freq)
+	hours, ok := seen[key]
+	if !ok {
+		hours = map[string]bool{}
+		seen[key] = hours
+	}
+	hours[t.Hour] = true
+	if len(hours) < 24 {
+		return
+	}
+	delete(seen, key)
+	animateCh <- animGroup{MapType: t.MapType, Year: t.Year, Month: t.Month, Freq: t.Freq, OutDir: t.OutDir}
+}
+
+// animateWorker renders every requested format for each complete group it
+// receives until jobs is closed. A failure on one format is logged and
+// does not prevent the others from being attempted.
+func animateWorker(ctx context.Context, jobs <-chan animGroup, wg *sync.WaitGroup, formats []string, ffmpegBin string, verbose bool) {
+	defer wg.Done()
+	for g := range jobs {
+		outBase := filepath.Join(g.OutDir, fmt.Sprintf("%sMHz", g.Freq))
+		for _, format := range formats {
+			out := outBase + "." + format
+			if err := renderAnimation(ctx, g, format, out, ffmpegBin); err != nil {
+				fmt.Fprintf(os.Stderr, "Animate failed [%s] %s %s/%s %sMHz: %v\n", g.MapType, format, g.Year, g.Month, g.Freq, err)
+				continue
+			}
+			if verbose {
+				fmt.Printf("Animated [%s] %s/%s %sMHz -> %s\n", g.MapType, g.Year, g.Month, g.Freq, out)
+			}
+		}
+	}
+}
+
+func renderAnimation(ctx context.Context, g animGroup, format, outPath, ffmpegBin string) error {
+	switch format {
+	case "gif", "apng":
+		frames := make([]string, 24)
+		for h := 0; h < 24; h++ {
+			frames[h] = filepath.Join(g.OutDir, fmt.Sprintf("%02dUT-%sMHz.png", h, g.Freq))
+		}
+		if format == "gif" {
+			return buildGIF(frames, outPath)
+		}
+		return buildAPNG(frames, outPath)
+	case "mp4", "webp":
+		// mp4 always needs ffmpeg for H.264. webp has no pure-Go encoder to
+		// fall back to either (x/image/webp only decodes, and the standard
+		// library has nothing for WebP at all), so it rides along with mp4
+		// here instead of getting the gif/apng pure-Go treatment.
+		return buildViaFFmpeg(ctx, ffmpegBin, g.OutDir, g.Freq, outPath, format)
+	default:
+		return fmt.Errorf("unknown animation format %q", format)
+	}
+}
+
+// buildGIF decodes each frame PNG, dithers it onto a fixed 256-color
+// palette (image/draw's Floyd-Steinberg drawer, stdlib-only, no external
+// quantizer), and writes a looping animated GIF.
+func buildGIF(framePaths []string, outPath string) error {
+	anim := &gif.GIF{}
+	for _, p := range framePaths {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("decoding %s: %w", p, err)
+		}
+		b := img.Bounds()
+		pal := image.NewPaletted(b, palette.Plan9)
+		draw.FloydSteinberg.Draw(pal, b, img, image.Point{})
+		anim.Image = append(anim.Image, pal)
+		anim.Delay = append(anim.Delay, 50) // 500ms/frame
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+
+	tmp := outPath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, outPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// apngFrame holds one frame's worth of chunks pulled out of a standalone
+// PNG encode, ready to be re-framed into an APNG by writeAPNG.
+type apngFrame struct {
+	ihdr, plte, trns []byte
+	idat             [][]byte
+}
+
+// buildAPNG decodes each frame PNG, dithers it the same way buildGIF does
+// so the two formats match, re-encodes each frame as a standalone PNG via
+// image/png, and hand-assembles the results into an Animated PNG: the same
+// IHDR/PLTE/tRNS plus an acTL chunk and one fcTL+IDAT (first frame) or
+// fcTL+fdAT (later frames) pair per frame. Neither the standard library nor
+// golang.org/x/image ships an APNG encoder, so re-framing image/png's own
+// IDAT output is the only pure-Go path to one.
+func buildAPNG(framePaths []string, outPath string) error {
+	frames := make([]apngFrame, len(framePaths))
+	for i, p := range framePaths {
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("decoding %s: %w", p, err)
+		}
+		b := img.Bounds()
+		pal := image.NewPaletted(b, palette.Plan9)
+		draw.FloydSteinberg.Draw(pal, b, img, image.Point{})
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, pal); err != nil {
+			return fmt.Errorf("encoding frame %s: %w", p, err)
+		}
+		chunks, err := readPNGChunks(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("re-reading encoded frame %s: %w", p, err)
+		}
+		for _, c := range chunks {
+			switch c.typ {
+			case "IHDR":
+				frames[i].ihdr = c.data
+			case "PLTE":
+				frames[i].plte = c.data
+			case "tRNS":
+				frames[i].trns = c.data
+			case "IDAT":
+				frames[i].idat = append(frames[i].idat, c.data)
+			}
+		}
+		if frames[i].ihdr == nil || len(frames[i].idat) == 0 {
+			return fmt.Errorf("re-encoded frame %s: missing IHDR or IDAT chunk", p)
+		}
+	}
+
+	tmp := outPath + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := writeAPNG(f, frames, 50, 100); err != nil { // 50/100s = 500ms/frame, matching buildGIF
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, outPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeAPNG assembles frames (each already a decoded standalone PNG's
+// chunks) into an APNG stream, looping forever at delayNum/delayDen
+// seconds per frame.
+func writeAPNG(w io.Writer, frames []apngFrame, delayNum, delayDen uint16) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+	if _, err := io.WriteString(w, "\x89PNG\r\n\x1a\n"); err != nil {
+		return err
+	}
+	if err := writePNGChunk(w, "IHDR", frames[0].ihdr); err != nil {
+		return err
+	}
+	if frames[0].plte != nil {
+		if err := writePNGChunk(w, "PLTE", frames[0].plte); err != nil {
+			return err
+		}
+	}
+	if frames[0].trns != nil {
+		if err := writePNGChunk(w, "tRNS", frames[0].trns); err != nil {
+			return err
+		}
+	}
+
+	var acTL [8]byte
+	binary.BigEndian.PutUint32(acTL[0:4], uint32(len(frames)))
+	binary.BigEndian.PutUint32(acTL[4:8], 0) // num_plays: 0 = loop forever
+	if err := writePNGChunk(w, "acTL", acTL[:]); err != nil {
+		return err
+	}
+
+	width := binary.BigEndian.Uint32(frames[0].ihdr[0:4])
+	height := binary.BigEndian.Uint32(frames[0].ihdr[4:8])
+
+	seq := uint32(0)
+	for i, fr := range frames {
+		var fcTL [26]byte
+		binary.BigEndian.PutUint32(fcTL[0:4], seq)
+		binary.BigEndian.PutUint32(fcTL[4:8], width)
+		binary.BigEndian.PutUint32(fcTL[8:12], height)
+		binary.BigEndian.PutUint32(fcTL[12:16], 0) // x_offset
+		binary.BigEndian.PutUint32(fcTL[16:20], 0) // y_offset
+		binary.BigEndian.PutUint16(fcTL[20:22], delayNum)
+		binary.BigEndian.PutUint16(fcTL[22:24], delayDen)
+		fcTL[24] = 0 // dispose_op: none
+		fcTL[25] = 0 // blend_op: source
+		seq++
+		if err := writePNGChunk(w, "fcTL", fcTL[:]); err != nil {
+			return err
+		}
+
+		if i == 0 {
+			for _, d := range fr.idat {
+				if err := writePNGChunk(w, "IDAT", d); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for _, d := range fr.idat {
+			fdAT := make([]byte, 4+len(d))
+			binary.BigEndian.PutUint32(fdAT[0:4], seq)
+			copy(fdAT[4:], d)
+			seq++
+			if err := writePNGChunk(w, "fdAT", fdAT); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writePNGChunk(w, "IEND", nil)
+}
+
+// pngChunk is one length-prefixed chunk read back out of a PNG image/png
+// just encoded, so its IDAT payload can be re-framed into an APNG fdAT.
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// readPNGChunks parses b (a full PNG file, signature included) into its
+// constituent chunks in file order.
+func readPNGChunks(b []byte) ([]pngChunk, error) {
+	sig := "\x89PNG\r\n\x1a\n"
+	if len(b) < len(sig) || string(b[:len(sig)]) != sig {
+		return nil, fmt.Errorf("not a PNG stream")
+	}
+	b = b[len(sig):]
+	var chunks []pngChunk
+	for len(b) >= 8 {
+		length := binary.BigEndian.Uint32(b[0:4])
+		typ := string(b[4:8])
+		if uint32(len(b)) < 8+length+4 {
+			return nil, fmt.Errorf("truncated %s chunk", typ)
+		}
+		data := append([]byte(nil), b[8:8+length]...)
+		chunks = append(chunks, pngChunk{typ: typ, data: data})
+		b = b[8+length+4:]
+	}
+	return chunks, nil
+}
+
+// writePNGChunk writes one length-prefixed, CRC-terminated PNG chunk.
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(data)))
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	if _, err := io.WriteString(w, typ); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	var crcBuf [4]byte
+	binary.BigEndian.PutUint32(crcBuf[:], crc.Sum32())
+	_, err := w.Write(crcBuf[:])
+	return err
+}
+
+// buildViaFFmpeg assembles outDir's hourly PNGs for freq into outPath by
+// globbing them in ffmpeg itself; the zero-padded "HHUT-FFMHz.png" naming
+// already sorts into hour order lexicographically, so no intermediate
+// renaming/concat list is needed.
+func buildViaFFmpeg(ctx context.Context, ffmpegBin, outDir, freq, outPath, format string) error {
+	pattern := filepath.Join(outDir, fmt.Sprintf("*UT-%sMHz.png", freq))
+	args := []string{"-y", "-framerate", "2", "-pattern_type", "glob", "-i", pattern}
+	switch format {
+	case "mp4":
+		args = append(args, "-c:v", "libx264", "-pix_fmt", "yuv420p")
+	case "webp":
+		args = append(args, "-loop", "0")
+	}
+	args = append(args, outPath)
+
+	cmd := exec.CommandContext(ctx, ffmpegBin, args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, out)
+	}
+	return nil
+}
+
+func parseSelectedMaps(raw string) ([]string, error) {
+	r := strings.TrimSpace(strings.ToUpper(raw))
+	if r == "ALL" {
+		out := make([]string, 0, len(mapTypes))
+		for k := range mapTypes {
+			out = append(out, k)
+		}
+		sort.Strings(out)
+		return out, nil
+	}
+	parts := strings.Split(r, ",")
+	seen := map[string]bool{}
+	var sel []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, ok := mapTypes[p]; !ok {
+			return nil, fmt.Errorf("unknown map type %q (valid: %s)", p, strings.Join(keys(mapTypes), ","))
+		}
+		if !seen[p] {
+			seen[p] = true
+			sel = append(sel, p)
+		}
+	}
+	sort.Strings(sel)
+	return sel, nil
+}
+
+func keys(m map[string]mapCfg) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+type task struct {
+	VOA     string
+	VG      string
+	VGNum   string
+	MapType string
+	DFlag   string
+	OutDir  string
+	OutFile string
+	Year    string
+	Month   string
+	Hour    string
+	Freq    string
+}
+
+// taskKey identifies a task independently of filesystem layout, for use by
+// --shard/--shards sharding and --run regexp filtering.
+func taskKey(t task) string {
+	return fmt.Sprintf("%s/%s/%s/%sUT-%sMHz", t.MapType, t.Year, t.Month, t.Hour, t.Freq)
+}
+
+// shardOf deterministically assigns key to one of n shards via FNV-1a, so
+// that a given task always lands on the same shard across repeated runs.
+func shardOf(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+type taskOutcome int
+
+const (
+	outcomeSucceeded taskOutcome = iota
+	outcomeSkipped
+	outcomeFailed
+	outcomeTimedOut
+)
+
+type taskResult struct {
+	Task    task
+	Outcome taskOutcome
+}
+
+type mapTypeStats struct {
+	Succeeded, Skipped, Failed, TimedOut int
+}
+
+func worker(ctx context.Context, jobs <-chan task, wg *sync.WaitGroup, python, plot string, perTimeout time.Duration, done chan<- taskResult, verbose, keepGoing bool, cancelWork context.CancelFunc, engine string) {
+	defer wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-jobs:
+			if !ok {
+				return
+			}
+			// If the target already exists (race), mark done and skip
+			if _, err := os.Stat(t.OutFile); err == nil {
+				if done != nil {
+					done <- taskResult{t, outcomeSkipped}
+				}
+				continue
+			}
+			if err := os.MkdirAll(t.OutDir, 0o755); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: mkdir %s: %v\n", t.OutDir, err)
+				if done != nil {
+					done <- taskResult{t, outcomeFailed}
+				}
+				if !keepGoing {
+					cancelWork()
+				}
+				continue
+			}
+
+			taskCtx, cancel := context.WithTimeout(ctx, perTimeout)
+			start := time.Now()
+			var err error
+			var timedOut bool
+			if engine == "native" {
+				err = renderNative(taskCtx, t)
+				timedOut = taskCtx.Err() == context.DeadlineExceeded
+			} else {
+				cmd := exec.CommandContext(taskCtx, python, plot, "-f", "-d", t.DFlag, "-o", t.OutFile, "-v", t.VGNum, t.VOA)
+				out, cmdErr := cmd.CombinedOutput()
+				timedOut = taskCtx.Err() == context.DeadlineExceeded
+				if cmdErr != nil && !timedOut {
+					err = fmt.Errorf("%w\n%s", cmdErr, out)
+				} else {
+					err = cmdErr
+				}
+			}
+			cancel()
+			dur := time.Since(start)
+
+			switch {
+			case timedOut:
+				fmt.Fprintf(os.Stderr, "Timeout plotting %s for %s\n", t.MapType, filepath.Base(t.VG))
+				if done != nil {
+					done <- taskResult{t, outcomeTimedOut}
+				}
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "Plot failed [%s] %s -> %s: %v\n",
+					t.MapType, filepath.Base(t.VG), filepath.Base(t.OutFile), err)
+				if done != nil {
+					done <- taskResult{t, outcomeFailed}
+				}
+				if !keepGoing {
+					cancelWork()
+				}
+			default:
+				if verbose {
+					fmt.Printf("OK [%s] %s (%s)\n", t.MapType, filepath.Base(t.OutFile), dur.Truncate(time.Millisecond))
+				}
+				if done != nil {
+					done <- taskResult{t, outcomeSucceeded}
+				}
+			}
+		}
+	}
+}
+
+// renderNative renders t in-process via the plotter package instead of
+// shelling out to pythonExe+plotScriptPath. Unlike the exec.CommandContext
+// path, there's no OS process to kill on deadline, so plotter.Render polls
+// ctx itself between scanlines and returns ctx.Err() as soon as it notices
+// the deadline has passed, instead of running the whole canvas to
+// completion and only being mislabeled "timed out" afterwards.
+func renderNative(ctx context.Context, t task) error {
+	err := plotter.Render(ctx, plotter.Options{
+		VOAPath: t.VOA,
+		VGPath:  t.VG,
+		MapType: plotter.MapType(t.MapType),
+		OutFile: t.OutFile,
+	})
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return ctx.Err()
+	}
+	return err
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// --------------- Minimal interactive helpers ---------------
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func ask(prompt string) string {
+	fmt.Print(prompt)
+	s, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(s)
+}
+
+func askUntilValidDir(prompt string) string {
+	for {
+		p := ask(prompt)
+		if p == "" {
+			continue
+		}
+		p = filepath.Clean(p)
+		if st, err := os.Stat(p); err == nil && st.IsDir() {
+			return p
+		}
+		fmt.Println("Path does not exist or is not a directory. Try again.")
+	}
+}
@@ -0,0 +1,567 @@
+package main
+
+// --serve mode: a long-lived process that polls ROOT for new VOACAP output
+// instead of being pointed at a finished run, and exposes progress/catalog
+// over HTTP. It reuses the same task type, worker pool, and taskResult
+// outcomes as the one-shot batch path in main.go.
+//
+// HTTP endpoints:
+//
+//	GET  /api/progress  JSON {completed,failed,total}, or an SSE stream of
+//	                     the same object (one event per task completion and
+//	                     per rescan of ROOT) if the request's Accept header
+//	                     includes text/event-stream
+//	GET  /api/maps       JSON catalog of rendered PNGs, newest scan
+//	GET  /maps/...       static file server rooted at ROOT
+//	POST /api/replot     JSON body {"pattern": "<regexp>"}; matches task keys
+//	                     (MapType/Year/Month/HHUT-FFMHz) and forces those
+//	                     tasks to re-render, bypassing the checkpoint
+//
+// A note on file watching: new/changed files are picked up event-driven via
+// a Linux inotify watcher (watch_linux.go), built directly on the standard
+// library's "syscall" package rather than fsnotify — every other native
+// feature in this package avoids a dependency outside the standard library,
+// and taking on the module's first external one wasn't a call to make
+// unilaterally. pollLoop rescans on its ticker every pollInterval in every
+// case; an inotify event only triggers an extra, earlier scan. On non-Linux
+// platforms (watch_other.go), or if the inotify watcher fails to start, the
+// ticker is the only trigger, so new files show up as plot tasks within one
+// poll interval instead of right away.
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/OH6BG/VOACAP_MAPS/voafile"
+)
+
+const (
+	checkpointFileName = ".plotmaps-state.json"
+	pollInterval       = 5 * time.Second
+	retryBaseDelay     = 30 * time.Second
+	retryMaxDelay      = 30 * time.Minute
+	maxRetryAttempts   = 8
+)
+
+// taskState is one task's last known outcome, persisted across restarts so
+// a server doesn't redo work it already finished, even if the output PNG
+// has since been moved elsewhere (the checkpoint is keyed by taskKey, not
+// by output path).
+type taskState struct {
+	Outcome     string    `json:"outcome"` // "succeeded" or "failed"
+	Attempts    int       `json:"attempts"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	NextRetry   time.Time `json:"next_retry,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	OutputMtime time.Time `json:"output_mtime,omitempty"` // the rendered PNG's mtime as of the last success, used to detect a regenerated source .vg file
+}
+
+type checkpoint struct {
+	Tasks map[string]*taskState `json:"tasks"`
+}
+
+type progressSnapshot struct {
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"` // given up after maxRetryAttempts
+	Total     int `json:"total"`
+}
+
+type mapEntry struct {
+	MapType string `json:"map_type"`
+	Year    string `json:"year"`
+	Month   string `json:"month"`
+	Hour    string `json:"hour"`
+	Freq    string `json:"freq"`
+	Path    string `json:"path"` // relative to root; fetch via GET /maps/<path>
+}
+
+// server holds all --serve mode state: the checkpoint, the most recent scan
+// of ROOT (known), and SSE subscribers. mu guards cp and known together
+// since handlers read both to build a consistent snapshot.
+type server struct {
+	root           string
+	selected       []string
+	engine         string
+	verbose        bool
+	checkpointPath string
+
+	tasks chan task
+	done  chan taskResult
+
+	mu    sync.Mutex
+	cp    checkpoint
+	known map[string]task // taskKey -> most recently scanned task
+
+	subsMu sync.Mutex
+	subs   map[chan progressSnapshot]struct{}
+}
+
+func newServer(root string, selected []string, engine string, verbose bool) *server {
+	return &server{
+		root:           root,
+		selected:       selected,
+		engine:         engine,
+		verbose:        verbose,
+		checkpointPath: filepath.Join(root, checkpointFileName),
+		cp:             checkpoint{Tasks: map[string]*taskState{}},
+		known:          map[string]task{},
+		subs:           map[chan progressSnapshot]struct{}{},
+	}
+}
+
+// runServe starts the worker pool, the poll loop, and the HTTP server, and
+// blocks until Ctrl+C/SIGTERM, then waits for the workers to exit before
+// returning. Workers stop on the canceled context, so a render still in
+// flight at that point is abandoned, not finished.
+func runServe(root string, selected []string, engine, addr string, workers int, verbose bool) error {
+	s := newServer(root, selected, engine, verbose)
+	s.loadCheckpoint()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s.tasks = make(chan task, 1024)
+	s.done = make(chan taskResult, 4096)
+
+	var wg sync.WaitGroup
+	nw := max(1, workers)
+	noCancel := func() {} // worker's keep-going=false path is unreachable here: the server never self-cancels on a single task failure
+	for i := 0; i < nw; i++ {
+		wg.Add(1)
+		go worker(ctx, s.tasks, &wg, pythonExe, plotScriptPath, perPlotTimeout, s.done, verbose, true, noCancel, engine)
+	}
+
+	go s.collectResults(ctx)
+	go s.pollLoop(ctx)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/progress", s.handleProgress)
+	mux.HandleFunc("/api/maps", s.handleMaps)
+	mux.HandleFunc("/api/replot", s.handleReplot)
+	mux.Handle("/maps/", http.StripPrefix("/maps/", http.FileServer(http.Dir(root))))
+
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving on %s (root=%s, engine=%s)\n", addr, root, engine)
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	close(s.tasks)
+	wg.Wait()
+	close(s.done)
+	return nil
+}
+
+// pollLoop rescans root on every tick, enqueuing any task not already
+// recorded as succeeded (or still inside its retry backoff window).
+func (s *server) pollLoop(ctx context.Context) {
+	changed := make(chan struct{}, 1)
+	if w, err := newInotifyWatcher(s.root); err == nil {
+		go w.run(ctx, changed)
+	} else {
+		fmt.Fprintf(os.Stderr, "serve: inotify watching unavailable (%v); scanning every %s instead\n", err, pollInterval)
+	}
+
+	s.scan(ctx)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+			s.scan(ctx)
+		case <-ticker.C:
+			s.scan(ctx)
+		}
+	}
+}
+
+func (s *server) scan(ctx context.Context) {
+	sets, err := voafile.Scan(s.root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "serve: scan %s: %v\n", s.root, err)
+		return
+	}
+
+	discovered := make(map[string]task)
+	for _, set := range sets {
+		for _, vg := range set.VGs {
+			hh := fmt.Sprintf("%02d", vg.Hour)
+			ff := fmt.Sprintf("%02.0f", vg.FreqMHz)
+			for _, m := range s.selected {
+				cfg := mapTypes[m]
+				outDir := filepath.Join(s.root, cfg.Dir, set.Year, set.Month)
+				outFile := filepath.Join(outDir, fmt.Sprintf("%sUT-%sMHz.png", hh, ff))
+				t := task{
+					VOA:     set.Path,
+					VG:      vg.Path,
+					VGNum:   strconv.Itoa(vg.Index),
+					MapType: m,
+					DFlag:   cfg.DFlag,
+					OutDir:  outDir,
+					OutFile: outFile,
+					Year:    set.Year,
+					Month:   set.Month,
+					Hour:    hh,
+					Freq:    ff,
+				}
+				discovered[taskKey(t)] = t
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.known = discovered
+	s.mu.Unlock()
+
+	for key, t := range discovered {
+		if s.shouldSkip(key, t) {
+			continue
+		}
+		os.Remove(t.OutFile) // worker skips rendering if the target already exists
+		select {
+		case s.tasks <- t:
+		case <-ctx.Done():
+			return
+		default:
+			// Queue is momentarily full; this task is still in `known` and
+			// will be offered again on the next poll tick.
+		}
+	}
+	s.broadcastProgress()
+}
+
+// shouldSkip reports whether key's last known outcome means scan should
+// not re-enqueue t: already succeeded with no newer source data, still
+// permanently failed, or still inside its exponential-backoff retry
+// window. A "succeeded" task is re-enqueued anyway if t.VG's mtime is
+// newer than the OutputMtime recorded at that success — the case a
+// running VOACAP job overwrites an hour/freq's .vg file with updated
+// data after this server already rendered it once.
+func (s *server) shouldSkip(key string, t task) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.cp.Tasks[key]
+	if !ok {
+		return false
+	}
+	switch st.Outcome {
+	case "succeeded":
+		if !st.OutputMtime.IsZero() {
+			if info, err := os.Stat(t.VG); err == nil && info.ModTime().After(st.OutputMtime) {
+				return false
+			}
+		}
+		return true
+	case "failed":
+		if st.Attempts > maxRetryAttempts {
+			return true
+		}
+		return time.Now().Before(st.NextRetry)
+	default:
+		return false
+	}
+}
+
+func (s *server) collectResults(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case r, ok := <-s.done:
+			if !ok {
+				return
+			}
+			s.recordResult(r)
+			s.saveCheckpoint()
+			s.broadcastProgress()
+		}
+	}
+}
+
+func (s *server) recordResult(r taskResult) {
+	key := taskKey(r.Task)
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.cp.Tasks[key]
+	if !ok {
+		st = &taskState{}
+		s.cp.Tasks[key] = st
+	}
+	st.UpdatedAt = now
+	switch r.Outcome {
+	case outcomeSucceeded, outcomeSkipped:
+		st.Outcome = "succeeded"
+		st.Attempts = 0
+		st.NextRetry = time.Time{}
+		st.Error = ""
+		st.OutputMtime = time.Time{}
+		if info, err := os.Stat(r.Task.OutFile); err == nil {
+			st.OutputMtime = info.ModTime()
+		}
+	case outcomeFailed, outcomeTimedOut:
+		st.Attempts++
+		st.Outcome = "failed"
+		if r.Outcome == outcomeTimedOut {
+			st.Error = "timed out"
+		} else {
+			st.Error = "plot failed"
+		}
+		st.NextRetry = now.Add(backoffDelay(st.Attempts))
+	}
+}
+
+// backoffDelay doubles retryBaseDelay per attempt, capped at retryMaxDelay.
+func backoffDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 20 { // guard the bit shift below against overflow
+		attempts = 20
+	}
+	d := retryBaseDelay * time.Duration(uint64(1)<<uint(attempts-1))
+	if d <= 0 || d > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return d
+}
+
+func (s *server) loadCheckpoint() {
+	data, err := os.ReadFile(s.checkpointPath)
+	if err != nil {
+		return // no prior checkpoint is a fresh start, not an error
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		fmt.Fprintf(os.Stderr, "serve: ignoring unreadable checkpoint %s: %v\n", s.checkpointPath, err)
+		return
+	}
+	if cp.Tasks == nil {
+		cp.Tasks = map[string]*taskState{}
+	}
+	s.mu.Lock()
+	s.cp = cp
+	s.mu.Unlock()
+}
+
+// saveCheckpoint writes the checkpoint via a temp file + rename, the same
+// atomic-write convention used by run_p2p's deck writer and plotter's PNG
+// writer, so a crash mid-write never leaves a corrupt checkpoint behind.
+func (s *server) saveCheckpoint() {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.cp, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "serve: marshal checkpoint: %v\n", err)
+		return
+	}
+	tmp := s.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "serve: write checkpoint: %v\n", err)
+		return
+	}
+	if err := os.Rename(tmp, s.checkpointPath); err != nil {
+		fmt.Fprintf(os.Stderr, "serve: rename checkpoint: %v\n", err)
+		os.Remove(tmp)
+	}
+}
+
+func (s *server) snapshot() progressSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var snap progressSnapshot
+	snap.Total = len(s.known)
+	for key := range s.known {
+		st, ok := s.cp.Tasks[key]
+		if !ok {
+			continue
+		}
+		switch {
+		case st.Outcome == "succeeded":
+			snap.Completed++
+		case st.Outcome == "failed" && st.Attempts > maxRetryAttempts:
+			snap.Failed++
+		}
+	}
+	return snap
+}
+
+func (s *server) subscribe(ch chan progressSnapshot) {
+	s.subsMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subsMu.Unlock()
+}
+
+func (s *server) unsubscribe(ch chan progressSnapshot) {
+	s.subsMu.Lock()
+	delete(s.subs, ch)
+	s.subsMu.Unlock()
+}
+
+func (s *server) broadcastProgress() {
+	snap := s.snapshot()
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- snap:
+		default:
+			// subscriber is behind; it'll catch up on the next broadcast
+		}
+	}
+}
+
+func (s *server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.snapshot())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch := make(chan progressSnapshot, 8)
+	s.subscribe(ch)
+	defer s.unsubscribe(ch)
+
+	write := func(snap progressSnapshot) bool {
+		b, err := json.Marshal(snap)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	if !write(s.snapshot()) {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case snap := <-ch:
+			if !write(snap) {
+				return
+			}
+		}
+	}
+}
+
+func (s *server) handleMaps(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	known := make(map[string]task, len(s.known))
+	for k, t := range s.known {
+		known[k] = t
+	}
+	s.mu.Unlock()
+
+	entries := make([]mapEntry, 0, len(known))
+	for _, t := range known {
+		if _, err := os.Stat(t.OutFile); err != nil {
+			continue // not rendered (yet)
+		}
+		rel, err := filepath.Rel(s.root, t.OutFile)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, mapEntry{
+			MapType: t.MapType,
+			Year:    t.Year,
+			Month:   t.Month,
+			Hour:    t.Hour,
+			Freq:    t.Freq,
+			Path:    filepath.ToSlash(rel),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch {
+		case a.MapType != b.MapType:
+			return a.MapType < b.MapType
+		case a.Year != b.Year:
+			return a.Year < b.Year
+		case a.Month != b.Month:
+			return a.Month < b.Month
+		case a.Freq != b.Freq:
+			return a.Freq < b.Freq
+		default:
+			return a.Hour < b.Hour
+		}
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (s *server) handleReplot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Pattern string `json:"pattern"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Pattern) == "" {
+		http.Error(w, `expected a JSON body {"pattern": "<regexp>"}`, http.StatusBadRequest)
+		return
+	}
+	re, err := regexp.Compile(body.Pattern)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid pattern: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	var matched []task
+	for key, t := range s.known {
+		if re.MatchString(key) {
+			delete(s.cp.Tasks, key)
+			matched = append(matched, t)
+		}
+	}
+	s.mu.Unlock()
+	s.saveCheckpoint()
+
+	for _, t := range matched {
+		os.Remove(t.OutFile) // worker skips rendering if the target already exists
+		select {
+		case s.tasks <- t:
+		default:
+			// queue is momentarily full; the next poll tick will pick it up
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"queued": len(matched)})
+}
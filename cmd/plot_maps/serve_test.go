@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBackoffDelay checks the doubling sequence and the retryMaxDelay cap.
+func TestBackoffDelay(t *testing.T) {
+	if got := backoffDelay(1); got != retryBaseDelay {
+		t.Errorf("backoffDelay(1) = %v, want %v", got, retryBaseDelay)
+	}
+	if got := backoffDelay(2); got != retryBaseDelay*2 {
+		t.Errorf("backoffDelay(2) = %v, want %v", got, retryBaseDelay*2)
+	}
+	if got := backoffDelay(0); got != retryBaseDelay {
+		t.Errorf("backoffDelay(0) = %v, want %v (clamped to attempt 1)", got, retryBaseDelay)
+	}
+	if got := backoffDelay(20); got != retryMaxDelay {
+		t.Errorf("backoffDelay(20) = %v, want %v (capped)", got, retryMaxDelay)
+	}
+}
+
+func writeTestFile(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestShouldSkip covers the three outcomes shouldSkip can report for a
+// known task key: no record (don't skip), succeeded (skip, unless the
+// source .vg file is newer than the recorded render), and failed (skip
+// only while inside the backoff window or after attempts are exhausted).
+func TestShouldSkip(t *testing.T) {
+	root := t.TempDir()
+	vgPath := filepath.Join(root, "cap.vg1")
+	outPath := filepath.Join(root, "out.png")
+	base := time.Now().Add(-time.Hour)
+	writeTestFile(t, vgPath, base)
+	writeTestFile(t, outPath, base)
+	tk := task{VG: vgPath, OutFile: outPath}
+
+	s := newServer(root, nil, "native", false)
+
+	if s.shouldSkip("unknown", tk) {
+		t.Error("shouldSkip with no recorded state: want false")
+	}
+
+	s.cp.Tasks["succeeded-fresh"] = &taskState{Outcome: "succeeded", OutputMtime: base.Add(time.Minute)}
+	if !s.shouldSkip("succeeded-fresh", tk) {
+		t.Error("shouldSkip for succeeded task with an up-to-date source: want true")
+	}
+
+	s.cp.Tasks["succeeded-stale"] = &taskState{Outcome: "succeeded", OutputMtime: base.Add(-time.Minute)}
+	if s.shouldSkip("succeeded-stale", tk) {
+		t.Error("shouldSkip for succeeded task whose .vg source is newer than the render: want false (re-render)")
+	}
+
+	s.cp.Tasks["failed-backoff"] = &taskState{Outcome: "failed", Attempts: 1, NextRetry: time.Now().Add(time.Hour)}
+	if !s.shouldSkip("failed-backoff", tk) {
+		t.Error("shouldSkip for a failed task still inside its backoff window: want true")
+	}
+
+	s.cp.Tasks["failed-retryable"] = &taskState{Outcome: "failed", Attempts: 1, NextRetry: time.Now().Add(-time.Hour)}
+	if s.shouldSkip("failed-retryable", tk) {
+		t.Error("shouldSkip for a failed task past its backoff window: want false")
+	}
+
+	s.cp.Tasks["failed-exhausted"] = &taskState{Outcome: "failed", Attempts: maxRetryAttempts + 1}
+	if !s.shouldSkip("failed-exhausted", tk) {
+		t.Error("shouldSkip for a failed task past maxRetryAttempts: want true")
+	}
+}
+
+// TestRecordResult checks that a success stamps OutputMtime from the
+// rendered file and clears retry state, and that a failure increments
+// Attempts and schedules NextRetry.
+func TestRecordResult(t *testing.T) {
+	root := t.TempDir()
+	outPath := filepath.Join(root, "out.png")
+	mtime := time.Now().Add(-time.Minute)
+	writeTestFile(t, outPath, mtime)
+
+	s := newServer(root, nil, "native", false)
+	tsk := task{MapType: "MUF", Year: "2026", Month: "Jul", Hour: "12", Freq: "14", OutFile: outPath}
+	key := taskKey(tsk)
+
+	s.recordResult(taskResult{Task: tsk, Outcome: outcomeSucceeded})
+	st := s.cp.Tasks[key]
+	if st == nil || st.Outcome != "succeeded" {
+		t.Fatalf("after success: Tasks[%q] = %+v, want Outcome=succeeded", key, st)
+	}
+	if !st.OutputMtime.Equal(mtime) {
+		t.Errorf("OutputMtime = %v, want %v (the output file's mtime)", st.OutputMtime, mtime)
+	}
+
+	s.recordResult(taskResult{Task: tsk, Outcome: outcomeFailed})
+	st = s.cp.Tasks[key]
+	if st.Outcome != "failed" || st.Attempts != 1 {
+		t.Fatalf("after failure: %+v, want Outcome=failed Attempts=1", st)
+	}
+	if !st.NextRetry.After(time.Now()) {
+		t.Errorf("NextRetry = %v, want in the future", st.NextRetry)
+	}
+}
+
+// TestScanReRendersStaleOutput drives the full scan -> s.tasks -> worker ->
+// collectResults pipeline for a task whose .vg source is newer than its
+// last recorded render. It guards against scan() re-enqueuing the stale
+// task without removing its old OutFile: if that file is left in place,
+// worker's "already exists" shortcut skips the task forever and
+// recordResult just re-stamps the same stale OutputMtime on every poll.
+func TestScanReRendersStaleOutput(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "2026", "Jul")
+	writeTestFile(t, filepath.Join(dir, "cap_014.100.voa"), time.Now())
+	if err := os.WriteFile(filepath.Join(dir, "cap_014.100.voa"), []byte(
+		"Transmit : 45.00   10.00   TEST                 G3\n"+
+			"Area     :    -180.0     180.0     -90.0      90.0\n"+
+			"Gridsize :    2    1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vgPath := filepath.Join(dir, "cap_014.100.vg1")
+	vgTime := time.Now()
+	writeTestFile(t, vgPath, vgTime)
+	if err := os.WriteFile(vgPath, []byte("12 UT   14 MHz\n1.0 2.0 3.0 4.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outFile := filepath.Join(root, "MUF", "2026", "Jul", "12UT-14MHz.png")
+	writeTestFile(t, outFile, vgTime.Add(-time.Hour)) // stale: rendered before the .vg above was last touched
+
+	s := newServer(root, []string{"MUF"}, "native", false)
+	s.tasks = make(chan task, 8)
+	s.done = make(chan taskResult, 8)
+	s.cp.Tasks["MUF/2026/Jul/12UT-14MHz"] = &taskState{Outcome: "succeeded", OutputMtime: vgTime.Add(-time.Hour)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go worker(ctx, s.tasks, &wg, "", "", time.Second, s.done, false, true, func() {}, "native")
+
+	s.scan(ctx)
+
+	select {
+	case r := <-s.done:
+		if r.Outcome == outcomeSkipped {
+			t.Fatal("worker reported outcomeSkipped for a stale task: scan left the old OutFile on disk, so the re-render fix did not take effect")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker never reported a result for the re-enqueued stale task")
+	}
+
+	cancel()
+	close(s.tasks)
+	wg.Wait()
+}
+
+// TestCheckpointRoundTrip checks saveCheckpoint/loadCheckpoint preserve a
+// succeeded taskState's Outcome, UpdatedAt and OutputMtime.
+func TestCheckpointRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	s := newServer(root, nil, "native", false)
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	s.cp.Tasks["MUF/2026/Jul/12UT-14MHz"] = &taskState{
+		Outcome:     "succeeded",
+		Attempts:    0,
+		UpdatedAt:   mtime,
+		OutputMtime: mtime,
+	}
+	s.saveCheckpoint()
+
+	reloaded := newServer(root, nil, "native", false)
+	reloaded.loadCheckpoint()
+
+	st, ok := reloaded.cp.Tasks["MUF/2026/Jul/12UT-14MHz"]
+	if !ok {
+		t.Fatalf("loadCheckpoint: key missing, got %+v", reloaded.cp.Tasks)
+	}
+	if st.Outcome != "succeeded" || !st.OutputMtime.Equal(mtime) || !st.UpdatedAt.Equal(mtime) {
+		t.Errorf("loadCheckpoint: got %+v, want Outcome=succeeded OutputMtime/UpdatedAt=%v", st, mtime)
+	}
+}
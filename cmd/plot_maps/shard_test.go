@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestShardOfDeterministic pins that shardOf always assigns the same key
+// to the same shard for a fixed shard count, the property --shard/--shards
+// sharding depends on across repeated runs.
+func TestShardOfDeterministic(t *testing.T) {
+	key := taskKey(task{MapType: "MUF", Year: "2026", Month: "Jul", Hour: "14", Freq: "21"})
+	want := shardOf(key, 4)
+	for i := 0; i < 10; i++ {
+		if got := shardOf(key, 4); got != want {
+			t.Fatalf("shardOf(%q, 4) = %d on call %d, want %d (not deterministic)", key, got, i, want)
+		}
+	}
+}
+
+// TestShardOfCoversAllShards checks that a reasonably sized population of
+// distinct keys spreads across every shard, not just a subset -- a
+// regression guard against a hash/modulo mistake that silently drops a
+// whole shard's worth of tasks.
+func TestShardOfCoversAllShards(t *testing.T) {
+	const n = 4
+	seen := make([]bool, n)
+	for h := 0; h < 24; h++ {
+		for _, freq := range []string{"07", "14", "21", "28"} {
+			key := taskKey(task{MapType: "REL", Year: "2026", Month: "Jun", Hour: fmt.Sprintf("%02d", h), Freq: freq})
+			seen[shardOf(key, n)] = true
+		}
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("shard %d received no tasks out of %d distinct keys", i, n*24)
+		}
+	}
+}
+
+// TestTaskKeyDistinguishesHourAndFreq ensures taskKey doesn't collapse
+// two otherwise-identical tasks that only differ by hour or frequency,
+// since --run/--shard both key off it.
+func TestTaskKeyDistinguishesHourAndFreq(t *testing.T) {
+	base := task{MapType: "MUF", Year: "2026", Month: "Jul", Hour: "14", Freq: "21"}
+	byHour := base
+	byHour.Hour = "15"
+	byFreq := base
+	byFreq.Freq = "28"
+	if taskKey(base) == taskKey(byHour) {
+		t.Error("taskKey ignores Hour")
+	}
+	if taskKey(base) == taskKey(byFreq) {
+		t.Error("taskKey ignores Freq")
+	}
+}
@@ -0,0 +1,117 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyWatcher watches root and every subdirectory under it for new or
+// rewritten files using Linux's inotify syscalls directly via the standard
+// library's "syscall" package — no fsnotify or other module, the same
+// no-dependencies-outside-stdlib rule every other feature in this package
+// follows. pollLoop treats an event as a cue to scan immediately instead of
+// waiting for the next ticker tick.
+type inotifyWatcher struct {
+	fd      int
+	wdToDir map[int32]string
+}
+
+const watchMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE
+
+// newInotifyWatcher opens an inotify instance and adds a watch for root and
+// every directory already under it. New subdirectories created later (e.g.
+// a VOACAP job starting a new year/month folder) are picked up in run,
+// which re-walks each one as it appears.
+func newInotifyWatcher(root string) (*inotifyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("inotify_init1: %w", err)
+	}
+	w := &inotifyWatcher{fd: fd, wdToDir: map[int32]string{}}
+	if err := w.watchTree(root); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("walking %s: %w", root, err)
+	}
+	return w, nil
+}
+
+// watchTree adds a watch for root and every directory under it. Called
+// both at startup and whenever run notices a new directory, so that a
+// subdirectory created (and possibly already populated, e.g. by a fast
+// os.MkdirAll of several levels at once) before its own watch exists still
+// gets covered instead of silently falling outside the watched set.
+func (w *inotifyWatcher) watchTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil // a vanished entry mid-walk isn't fatal; it just won't be watched
+		}
+		return w.addDir(path)
+	})
+}
+
+func (w *inotifyWatcher) addDir(path string) error {
+	// Re-adding an existing watch just updates its mask and returns the
+	// same wd, so wdToDir stays a 1:1 map even if watchTree revisits a
+	// directory it already covers.
+	wd, err := syscall.InotifyAddWatch(w.fd, path, watchMask)
+	if err != nil {
+		return fmt.Errorf("inotify_add_watch %s: %w", path, err)
+	}
+	w.wdToDir[int32(wd)] = path
+	return nil
+}
+
+// run blocks reading inotify events until ctx is done, sending to changed
+// (non-blocking; a full channel just means a scan is already pending)
+// whenever something under root changes, and extending the watch to any
+// new subdirectory it notices.
+func (w *inotifyWatcher) run(ctx context.Context, changed chan<- struct{}) {
+	go func() {
+		<-ctx.Done()
+		syscall.Close(w.fd)
+	}()
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+256))
+	for {
+		n, err := syscall.Read(w.fd, buf)
+		if err != nil || n <= 0 {
+			return // fd closed on ctx.Done(), or an unrecoverable read error
+		}
+		offset := 0
+		for offset+syscall.SizeofInotifyEvent <= n {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameLen := int(raw.Len)
+			name := ""
+			if nameLen > 0 {
+				name = strings.TrimRight(string(buf[offset+syscall.SizeofInotifyEvent:offset+syscall.SizeofInotifyEvent+nameLen]), "\x00")
+			}
+			dir := w.wdToDir[raw.Wd]
+			offset += syscall.SizeofInotifyEvent + nameLen
+
+			if name != "" {
+				if full := filepath.Join(dir, name); raw.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+					if info, statErr := os.Stat(full); statErr == nil && info.IsDir() {
+						// watchTree, not addDir: by the time this event is
+						// processed, a fast multi-level os.MkdirAll may
+						// already have populated full with its own
+						// subdirectories, which still need watches.
+						w.watchTree(full) // best-effort: a failed add just falls back to the poll ticker
+					}
+				}
+			}
+
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestInotifyWatcherFiresOnNewFile checks that writing a new file under a
+// watched root produces an event on the watcher's changed channel.
+func TestInotifyWatcherFiresOnNewFile(t *testing.T) {
+	root := t.TempDir()
+	w, err := newInotifyWatcher(root)
+	if err != nil {
+		t.Fatalf("newInotifyWatcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go w.run(ctx, changed)
+
+	if err := os.WriteFile(filepath.Join(root, "cap.voa"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event fired after creating a file under the watched root")
+	}
+}
+
+// TestInotifyWatcherWatchesNewSubdir checks that a directory created after
+// the watcher starts is itself watched, since VOACAP creates year/month
+// subfolders lazily as a job progresses.
+func TestInotifyWatcherWatchesNewSubdir(t *testing.T) {
+	root := t.TempDir()
+	w, err := newInotifyWatcher(root)
+	if err != nil {
+		t.Fatalf("newInotifyWatcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go w.run(ctx, changed)
+
+	sub := filepath.Join(root, "2026", "Jul")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event fired after creating a subdirectory under the watched root")
+	}
+
+	// Give run's IN_CREATE handling a moment to add the new watch before
+	// writing into it.
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(sub, "cap.vg1"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event fired for a file written into a subdirectory created after the watcher started")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// inotifyWatcher's event-driven path is Linux-only: the syscalls it's
+// built on aren't available elsewhere in the standard library. Other
+// platforms fall back to pollLoop's ticker alone.
+type inotifyWatcher struct{}
+
+func newInotifyWatcher(root string) (*inotifyWatcher, error) {
+	return nil, errors.New("inotify watching is only implemented on linux")
+}
+
+func (w *inotifyWatcher) run(ctx context.Context, changed chan<- struct{}) {}
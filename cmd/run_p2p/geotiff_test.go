@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteGeoTIFF writes a small grid and checks the TIFF header, the
+// ImageWidth/ImageLength tags in the IFD, and that the float32 pixel
+// payload round-trips the grid's values in row-major order.
+func TestWriteGeoTIFF(t *testing.T) {
+	g := &AreaGrid{
+		LonMin: -10, LonMax: 10, LatMin: -5, LatMax: 5,
+		NLon: 2, NLat: 3,
+		Values: [][]float64{
+			{1, 2},
+			{3, 4},
+			{5, 6},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "out.tif")
+	if err := writeGeoTIFF(path, g); err != nil {
+		t.Fatalf("writeGeoTIFF: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	if len(data) < 8 || string(data[0:2]) != "II" || data[2] != 42 || data[3] != 0 {
+		t.Fatalf("bad TIFF magic header: %v", data[:8])
+	}
+
+	ifdOffset := binary.LittleEndian.Uint32(data[4:8])
+	numEntries := binary.LittleEndian.Uint16(data[ifdOffset : ifdOffset+2])
+	tags := map[uint16]uint32{}
+	for i := uint16(0); i < numEntries; i++ {
+		entry := data[int(ifdOffset)+2+int(i)*12:]
+		tag := binary.LittleEndian.Uint16(entry[0:2])
+		value := binary.LittleEndian.Uint32(entry[8:12])
+		tags[tag] = value
+	}
+	if tags[256] != uint32(g.NLon) {
+		t.Errorf("ImageWidth tag = %d, want %d", tags[256], g.NLon)
+	}
+	if tags[257] != uint32(g.NLat) {
+		t.Errorf("ImageLength tag = %d, want %d", tags[257], g.NLat)
+	}
+
+	const headerLen = 8
+	pixels := data[headerLen : headerLen+g.NLon*g.NLat*4]
+	i := 0
+	for _, row := range g.Values {
+		for _, want := range row {
+			got := float64(math.Float32frombits(binary.LittleEndian.Uint32(pixels[i:])))
+			if got != want {
+				t.Errorf("pixel %d = %v, want %v", i/4, got, want)
+			}
+			i += 4
+		}
+	}
+}
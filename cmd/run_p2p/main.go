@@ -0,0 +1,1739 @@
+/*
+
+Copyright 2025 Jari Perkiömäki OH6BG
+
+Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+*/
+
+/*
+main.go (cmd/run_p2p) - produce VOACAP area prediction data by running voacapl.
+
+- Reads config from voacap.ini (default, frequency, antenna sections)
+- Prompts for years, months, start hour, and time range
+- Builds VOACAP .voa decks under /home/user/voacap_maps/predictions/<id>/<Year>/<Mon>/<freq>/
+- Invokes voacapl with area/calc
+- Cleans up temp files
+
+Usage examples:
+
+go run ./cmd/run_p2p
+go run ./cmd/run_p2p --workers 6
+
+Non-interactive (CI / scheduled) runs:
+
+go run ./cmd/run_p2p --manifest run.yaml
+go run ./cmd/run_p2p --manifest run.json --workers 8
+
+Manifest mode replaces the interactive year/month/hour prompts with a
+YAML or JSON file (selected by extension) and exits non-zero if any
+voacapl invocation fails, printing a one-line JSON summary to stdout.
+
+--log-format=json switches the per-item progress lines to newline-
+delimited JSON events (start/finish/error) instead of the default text
+lines. --checkpoint <file> records each completed (year,month,freq)
+tuple; re-run with --resume <id> (the prediction ID printed/returned by
+the earlier run) against the same --checkpoint file to skip work a
+crashed run already finished.
+
+Paths to voacapl, ITSHFBC and the SSN file are resolved, in priority
+order, from CLI flags, then VOACAP_* environment variables, then an
+OS-appropriate default (see resolvePaths):
+
+go run ./cmd/run_p2p --voacapl-bin /opt/itshfbc/voacapl --pred-dir D:\preds
+VOACAP_ITSHFBC_DIR=/srv/itshfbc go run ./cmd/run_p2p --manifest run.yaml
+
+--emit=geotiff,geojson post-processes each completed prediction's area
+output into a single-band float32 GeoTIFF (EPSG:4326) and/or a GeoJSON
+FeatureCollection of REL contour polygons (--contours, default
+0.25,0.5,0.75,0.9), written alongside the .voa file:
+
+go run ./cmd/run_p2p --emit=geotiff,geojson --contours=0.1,0.3,0.5,0.7,0.9
+
+Compile the code to an executable:
+
+go build -o run_p2p.exe ./cmd/run_p2p
+
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// [default]
+	TxLat    float64
+	TxLon    float64
+	Power    float64
+	Mode     int
+	Es       float64
+	Method   int
+	MinToa   float64
+	Noise    int
+	GridSize int
+	PathFlag string
+
+	// [frequency]
+	FList []string
+
+	// [antenna]
+	TxAnt map[string]string
+	RxAnt map[string]string
+}
+
+var (
+	// Defaults and paths; resolved at startup by resolvePaths() from flags,
+	// VOACAP_* env vars, then an OS-appropriate fallback.
+	basePredDir string
+	voacaplBin  string
+	itshfbcDir  string
+	ssnFile     string
+
+	monthsList = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	stdin      = bufio.NewReader(os.Stdin)
+
+	// User-settable workers (default 4)
+	workersFlag = flag.Int("workers", 4, "Max parallel voacapl runs")
+
+	// Non-interactive batch mode
+	manifestFlag = flag.String("manifest", "", "Path to a YAML/JSON run manifest (replaces interactive prompts)")
+
+	// Path overrides; empty means "use VOACAP_* env var, else OS default"
+	voacaplBinFlag = flag.String("voacapl-bin", "", "Path to the voacapl binary (env VOACAP_VOACAPL_BIN)")
+	itshfbcDirFlag = flag.String("itshfbc-dir", "", "Path to the ITSHFBC installation (env VOACAP_ITSHFBC_DIR)")
+	predDirFlag    = flag.String("pred-dir", "", "Base directory for prediction output (env VOACAP_PRED_DIR)")
+	ssnFileFlag    = flag.String("ssn-file", "", "Path to the SSN data file (env VOACAP_SSN_FILE)")
+
+	// Progress reporting and resumable runs
+	logFormatFlag  = flag.String("log-format", "text", "Progress event format: text or json")
+	checkpointFlag = flag.String("checkpoint", "", "Path to a checkpoint file recording completed (year,month,freq) tuples, for resumable runs")
+	resumeFlag     = flag.String("resume", "", "Resume a previous run by its prediction ID (pairs with --checkpoint)")
+
+	// Post-processing: coverage tiles alongside each .voa
+	emitFlag     = flag.String("emit", "", "Comma-separated post-processing outputs to write alongside each .voa: geotiff,geojson")
+	contoursFlag = flag.String("contours", "0.25,0.5,0.75,0.9", "Comma-separated REL thresholds for --emit=geojson contour polygons")
+)
+
+// Resolved from emitFlag/contoursFlag by resolveEmitTargets; read by
+// makeVOACAPPrediction's post-processing stage.
+var (
+	emitGeoTIFF       bool
+	emitGeoJSON       bool
+	contourThresholds []float64
+
+	// areaBounds mirrors the hardcoded "Area" line in the deck written by
+	// makeVOACAPPrediction: the whole globe, every run.
+	areaBounds = [4]float64{-180, 180, -90, 90}
+)
+
+func main() {
+	flag.Parse()
+	resolvePaths()
+	if err := resolveEmitTargets(); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Println("Create raw VOACAP prediction data for coverage maps.")
+	fmt.Println("Copyright 2025 Jari Perkiömäki OH6BG.")
+	fmt.Println()
+
+	// Read INI
+	cfg, err := readINI("voacap.ini")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: reading voacap.ini: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate voacapl
+	if st, err := os.Stat(voacaplBin); err != nil || st.IsDir() {
+		fmt.Fprintf(os.Stderr, "ERROR: voacapl binary not found: %s\n", voacaplBin)
+		os.Exit(1)
+	}
+	if st, err := os.Stat(itshfbcDir); err != nil || !st.IsDir() {
+		fmt.Fprintf(os.Stderr, "ERROR: ITSHFBC directory not found: %s\n", itshfbcDir)
+		os.Exit(1)
+	}
+
+	if strings.TrimSpace(*manifestFlag) != "" {
+		runManifest(cfg, *manifestFlag)
+		return
+	}
+
+	// Interactive prompts
+	runYears := askYears("Enter year(s): ")
+	runMonths := askMonths("Enter month number(s) (1..12): ")
+	startTime := askIntInRange("Enter start time UTC (0..23): ", 0, 23)
+	timeRange := askIntInRange("Enter time range in hours (1..24): ", 1, 24)
+
+	// Derived values
+	txName := latlon2loc(cfg.TxLat, cfg.TxLon, 3)
+	tLat := fmt.Sprintf("%6.2f", cfg.TxLat)
+	tLon := fmt.Sprintf("%7.2f", cfg.TxLon)
+
+	// Unique prediction ID (8 hex chars), or the one being resumed
+	preID := randomID8()
+	if strings.TrimSpace(*resumeFlag) != "" {
+		preID = strings.TrimSpace(*resumeFlag)
+	}
+	predRoot := filepath.Join(basePredDir, preID)
+	if err := os.MkdirAll(predRoot, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: cannot create base prediction directory %s: %v\n", predRoot, err)
+		os.Exit(1)
+	}
+
+	ckpt, err := loadCheckpoint(*checkpointFlag, preID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: loading checkpoint: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Total calculations for progress (one per frequency per year-month)
+	totalCalcs := len(cfg.FList) * len(runYears) * len(runMonths)
+	if totalCalcs == 0 {
+		fmt.Println("Nothing to do (no frequencies/years/months).")
+		return
+	}
+	fmt.Printf("Total calculations: %d\n", totalCalcs)
+	fmt.Printf("Prediction ID: %s\n", preID)
+
+	sink, err := newEventSink(*logFormatFlag, totalCalcs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Determine and print worker count
+	workersUsed := *workersFlag
+	if workersUsed < 1 {
+		workersUsed = 1
+	}
+	fmt.Printf("Total workers: %d\n", workersUsed)
+
+	// Timer start
+	start := time.Now()
+
+	for _, year := range runYears {
+		for _, month := range runMonths {
+			ssn := getSSN(ssnFile, year, month)
+			for ssn < 0 || ssn > 300 {
+				ssn = askIntInRange(fmt.Sprintf("\nEnter sunspot number (SSN) for %s %d: ", monthsList[month-1], year), 0, 300)
+			}
+			fmt.Printf("\nSSN for %s %d: %d\n\n", monthsList[month-1], year, ssn)
+
+			// Precompute repeated strings for hours block
+			hours := make([]int, timeRange)
+			for i := 0; i < timeRange; i++ {
+				hours[i] = (startTime + i) % 24
+			}
+			monthList := "Months   :" + repeatFloat(float64(month), len(hours), 7, 2)
+			ssnList := "Ssns     :" + repeatInt(ssn, len(hours), 7)
+			hourList := "Hours    :" + joinInts(hours, 7)
+
+			// Concurrency (bounded by --workers)
+			maxWorkers := workersUsed
+			if maxWorkers < 1 {
+				maxWorkers = 1
+			}
+			var wg sync.WaitGroup
+			sem := make(chan struct{}, maxWorkers)
+
+			for _, f := range cfg.FList {
+				freq := f // capture
+				year, month := year, month
+				wg.Add(1)
+				sem <- struct{}{}
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					_, _ = runOneItem(cfg, predRoot, preID, "", year, month, hours, monthList, ssnList, hourList, tLat, tLon, txName, ssn, freq, sink, ckpt)
+				}()
+			}
+			wg.Wait()
+		}
+	}
+
+	fmt.Printf("Elapsed: %s\n\n", time.Since(start).Truncate(time.Millisecond))
+
+	fmt.Printf("Output directory: %s\n", predRoot)
+}
+
+// Build deck, write atomically, run voacapl, cleanup. Returns an error
+// describing the first failure instead of logging directly, so manifest
+// mode can aggregate failures into its summary.
+func makeVOACAPPrediction(cfg Config, predRoot, siteDir string, year, month int, hours []int, monthList, ssnList, hourList, tLat, tLon, txName string, ssn int, freq string) error {
+	// Antenna mapping
+	txAnt, rxAnt := antForFreq(cfg, freq)
+
+	// Freq column repeated for hours
+	freqList := "Freqs    :" + repeatString(freq, len(hours), 7)
+
+	// Input deck content
+	deck := strings.Join([]string{
+		"Model    :VOACAP",
+		"Colors   :Black    :Blue     :Ignore   :Ignore   :Red      :Black with shading",
+		"Cities   :Receive.cty",
+		"Nparms   :    1",
+		"Parameter:REL      0",
+		fmt.Sprintf("Transmit : %s   %s   %-20s %s", tLat, tLon, txName, cfg.PathFlag),
+		fmt.Sprintf("Pcenter  : %s   %s   %-20s", tLat, tLon, txName),
+		"Area     :    -180.0     180.0     -90.0      90.0",
+		fmt.Sprintf("Gridsize :  %3d    1", cfg.GridSize),
+		fmt.Sprintf("Method   :   %d", cfg.Method),
+		"Coeffs   :CCIR",
+		monthList,
+		ssnList,
+		hourList,
+		freqList,
+		fmt.Sprintf("System   :  %3d     %.2f   90   %2d     3.000     0.100", cfg.Noise, cfg.MinToa, cfg.Mode),
+		fmt.Sprintf("Fprob    : 1.00 1.00 1.00 %.2f", cfg.Es),
+		fmt.Sprintf("Rec Ants :[voaant/%-14s]  gain=   0.0   0.0", rxAnt),
+		fmt.Sprintf("Tx Ants  :[voaant/%-14s]  0.000  -1.0   %8.4f", txAnt, cfg.Power),
+	}, "\n")
+
+	// Paths: ROOT/<site>/<Year>/<Mon>/<freq>/ (siteDir is "" for the
+	// single-site interactive flow, which keeps its existing layout).
+	monthName := monthsList[month-1]
+	runDir := filepath.Join(predRoot, siteDir, strconv.Itoa(year), monthName, freq)
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return fmt.Errorf("cannot create directory %s: %w", runDir, err)
+	}
+
+	// Deck file name: cap_<freq>.voa (06.3f)
+	fv, _ := strconv.ParseFloat(freq, 64)
+	voaName := fmt.Sprintf("cap_%06.3f.voa", fv)
+	voaPath := filepath.Join(runDir, voaName)
+
+	// Atomic write
+	tmp := voaPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(deck+"\n"), 0o644); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write temp deck %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, voaPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to move deck into place %s: %w", voaPath, err)
+	}
+
+	// Run voacapl (synchronously)
+	args := []string{
+		fmt.Sprintf("--run-dir=%s", runDir),
+		"--absorption-mode=a",
+		"-s",
+		itshfbcDir,
+		"area",
+		"calc",
+		voaName,
+	}
+	cmd := exec.Command(voacaplBin, args...)
+	cmd.Dir = runDir
+	var outBuf, errBuf strings.Builder
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("voacapl failed for %s MHz: %w\n%s", freq, err, errBuf.String())
+	}
+
+	// Post-processing: read the area grid voacapl left in runDir, before
+	// cleanup below removes it, and emit GeoTIFF/GeoJSON coverage tiles.
+	if emitGeoTIFF || emitGeoJSON {
+		if err := emitCoverageTiles(runDir, voaPath, cfg.GridSize); err != nil {
+			return fmt.Errorf("post-processing %s MHz: %w", freq, err)
+		}
+	}
+
+	// Cleanup
+	_ = os.Remove(filepath.Join(runDir, "type14.tmp"))
+	removeGlob(runDir, "*.da*")
+
+	// Do not print here to avoid interleaving; the progress lines handle reporting.
+	return nil
+}
+
+func removeGlob(dir, pattern string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+	for _, p := range matches {
+		_ = os.Remove(p)
+	}
+}
+
+// Antenna mapping per band (keys like "3.500", "14.100", etc.)
+func antForFreq(cfg Config, freq string) (string, string) {
+	key := freqKey(freq)
+	tx := cfg.TxAnt[key]
+	rx := cfg.RxAnt[key]
+	if tx == "" {
+		tx = cfg.TxAnt["28.200"] // default to 10m as in Python fallback
+	}
+	if rx == "" {
+		rx = cfg.RxAnt["28.200"]
+	}
+	return tx, rx
+}
+
+func freqKey(s string) string {
+	// Normalize to 1 decimal or 3 decimals like in INI keys
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "28.200"
+	}
+	// Keep as-is; INI uses exact strings (e.g., "14.100")
+	return s
+}
+
+// ===================== Path resolution =====================
+
+// resolvePaths fills in basePredDir, voacaplBin, itshfbcDir and ssnFile from,
+// in priority order: the --voacapl-bin/--itshfbc-dir/--pred-dir/--ssn-file
+// flags, then the matching VOACAP_* environment variable, then an
+// OS-appropriate default (runtime.GOOS). This lets Windows users running
+// voacapl.exe under C:\itshfbc work without editing source, same as the
+// historical Linux-only hardcoded globals did for /home/user layouts.
+func resolvePaths() {
+	basePredDir = firstNonEmpty(*predDirFlag, os.Getenv("VOACAP_PRED_DIR"), defaultPredDir())
+	voacaplBin = firstNonEmpty(*voacaplBinFlag, os.Getenv("VOACAP_VOACAPL_BIN"), defaultVoacaplBin())
+	itshfbcDir = firstNonEmpty(*itshfbcDirFlag, os.Getenv("VOACAP_ITSHFBC_DIR"), defaultItshfbcDir())
+	ssnFile = firstNonEmpty(*ssnFileFlag, os.Getenv("VOACAP_SSN_FILE"), defaultSSNFile())
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func defaultPredDir() string {
+	if runtime.GOOS == "windows" {
+		return `C:\itshfbc\voacap_maps\predictions`
+	}
+	return "/home/user/voacap_maps/predictions"
+}
+
+func defaultVoacaplBin() string {
+	if runtime.GOOS == "windows" {
+		return `C:\itshfbc\voacapl.exe`
+	}
+	return "/usr/local/bin/voacapl"
+}
+
+func defaultItshfbcDir() string {
+	if runtime.GOOS == "windows" {
+		return `C:\itshfbc`
+	}
+	return "/home/user/itshfbc"
+}
+
+func defaultSSNFile() string {
+	if runtime.GOOS == "windows" {
+		return `C:\itshfbc\voacap_maps\ssn.txt`
+	}
+	return "/home/user/voacap_maps/ssn.txt"
+}
+
+// ===================== Post-processing: coverage tiles =====================
+
+// resolveEmitTargets parses --emit/--contours into the package-level
+// emitGeoTIFF/emitGeoJSON/contourThresholds read by emitCoverageTiles.
+func resolveEmitTargets() error {
+	for _, t := range strings.Split(*emitFlag, ",") {
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "":
+		case "geotiff":
+			emitGeoTIFF = true
+		case "geojson":
+			emitGeoJSON = true
+		default:
+			return fmt.Errorf("unknown --emit target %q (want geotiff, geojson)", t)
+		}
+	}
+	if !emitGeoJSON {
+		return nil
+	}
+	for _, c := range strings.Split(*contoursFlag, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(c, 64)
+		if err != nil {
+			return fmt.Errorf("invalid --contours value %q: %w", c, err)
+		}
+		contourThresholds = append(contourThresholds, v)
+	}
+	return nil
+}
+
+// AreaGrid is a Gridsize x Gridsize REL grid over areaBounds, parsed from
+// voacapl's area output. Longitude is treated as cyclic (NLon points evenly
+// spaced over the full 360 degrees, not including a redundant +180 column)
+// so the antimeridian seam can be closed by marching-squares contouring
+// instead of leaving a gap at the grid edge. Latitude runs pole to pole
+// inclusive, so Values[0] is the north row and Values[NLat-1] the south row.
+type AreaGrid struct {
+	LonMin, LonMax, LatMin, LatMax float64
+	NLon, NLat                     int
+	Values                         [][]float64 // [latIdx][lonIdx]
+}
+
+// findAreaOutputFile locates the voacapl area/calc grid output in runDir.
+// voacapl leaves this as type1? before the caller's cleanup pass removes
+// it; when more than one matches, the most recently modified one wins,
+// the same convention newestVoaPerDir in the voafile package uses for .voa
+// files.
+func findAreaOutputFile(runDir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(runDir, "type1?"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no type1? area output found in %s", runDir)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		si, ei := os.Stat(matches[i])
+		sj, ej := os.Stat(matches[j])
+		if ei != nil || ej != nil {
+			return matches[i] < matches[j]
+		}
+		return si.ModTime().Before(sj.ModTime())
+	})
+	return matches[len(matches)-1], nil
+}
+
+// parseAreaGrid reads gridSize*gridSize whitespace-separated numeric
+// samples out of path, skipping any non-numeric header/label tokens, and
+// lays them out row-major north-to-south, west-to-east.
+func parseAreaGrid(path string, bounds [4]float64, gridSize int) (*AreaGrid, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	n := gridSize
+	if n < 2 {
+		n = 2
+	}
+	want := n * n
+	vals := make([]float64, 0, want)
+	for _, f := range strings.Fields(string(data)) {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			continue
+		}
+		vals = append(vals, v)
+		if len(vals) == want {
+			break
+		}
+	}
+	if len(vals) < want {
+		return nil, fmt.Errorf("%s: found %d numeric samples, want %d (%dx%d grid)", path, len(vals), want, n, n)
+	}
+
+	g := &AreaGrid{LonMin: bounds[0], LonMax: bounds[1], LatMin: bounds[2], LatMax: bounds[3], NLon: n, NLat: n}
+	g.Values = make([][]float64, n)
+	for i := 0; i < n; i++ {
+		g.Values[i] = vals[i*n : (i+1)*n]
+	}
+	return g, nil
+}
+
+func (g *AreaGrid) lonStep() float64    { return (g.LonMax - g.LonMin) / float64(g.NLon) }
+func (g *AreaGrid) latStep() float64    { return (g.LatMax - g.LatMin) / float64(g.NLat-1) }
+func (g *AreaGrid) lonAt(j int) float64 { return g.LonMin + float64(j)*g.lonStep() }
+func (g *AreaGrid) latAt(i int) float64 { return g.LatMax - float64(i)*g.latStep() }
+
+// emitCoverageTiles parses the area grid voacapl left in runDir and writes
+// a GeoTIFF and/or GeoJSON contour file next to voaPath (same basename,
+// .tif/.geojson extension), per the package-level emitGeoTIFF/emitGeoJSON
+// flags resolved by resolveEmitTargets.
+func emitCoverageTiles(runDir, voaPath string, gridSize int) error {
+	areaFile, err := findAreaOutputFile(runDir)
+	if err != nil {
+		return err
+	}
+	grid, err := parseAreaGrid(areaFile, areaBounds, gridSize)
+	if err != nil {
+		return err
+	}
+	base := strings.TrimSuffix(voaPath, filepath.Ext(voaPath))
+
+	if emitGeoTIFF {
+		if err := writeGeoTIFF(base+".tif", grid); err != nil {
+			return fmt.Errorf("writing GeoTIFF: %w", err)
+		}
+	}
+	if emitGeoJSON {
+		if err := writeGeoJSONContours(base+".geojson", grid, contourThresholds); err != nil {
+			return fmt.Errorf("writing GeoJSON: %w", err)
+		}
+	}
+	return nil
+}
+
+// ----- GeoTIFF -----
+
+// writeGeoTIFF encodes g as a single-band 32-bit float GeoTIFF in
+// geographic coordinates (EPSG:4326), using g's bounds for the affine
+// transform (ModelPixelScale + ModelTiepoint tags).
+func writeGeoTIFF(path string, g *AreaGrid) error {
+	nlon, nlat := g.NLon, g.NLat
+
+	pixels := make([]byte, nlon*nlat*4)
+	i := 0
+	for _, row := range g.Values {
+		for _, v := range row {
+			binary.LittleEndian.PutUint32(pixels[i:], math.Float32bits(float32(v)))
+			i += 4
+		}
+	}
+
+	geoKeys := []uint16{
+		1, 1, 0, 3, // KeyDirectoryVersion, KeyRevision, MinorRevision, NumberOfKeys
+		1024, 0, 1, 2, // GTModelTypeGeoKey = ModelTypeGeographic
+		1025, 0, 1, 1, // GTRasterTypeGeoKey = RasterPixelIsArea
+		2048, 0, 1, 4326, // GeographicTypeGeoKey = EPSG:4326 (WGS84)
+	}
+	geoKeyBytes := make([]byte, len(geoKeys)*2)
+	for k, v := range geoKeys {
+		binary.LittleEndian.PutUint16(geoKeyBytes[k*2:], v)
+	}
+	pixelScaleBytes := float64sToBytes([]float64{g.lonStep(), g.latStep(), 0})
+	tiepointBytes := float64sToBytes([]float64{0, 0, 0, g.LonMin, g.LatMax, 0})
+
+	const headerLen = 8
+	pixelOffset := headerLen
+	geoKeyOffset := pixelOffset + len(pixels)
+	pixelScaleOffset := geoKeyOffset + len(geoKeyBytes)
+	tiepointOffset := pixelScaleOffset + len(pixelScaleBytes)
+	ifdOffset := tiepointOffset + len(tiepointBytes)
+
+	entries := [][]byte{
+		tiffEntry(256, 4, 1, uint32(nlon)),                              // ImageWidth
+		tiffEntry(257, 4, 1, uint32(nlat)),                              // ImageLength
+		tiffEntry(258, 3, 1, 32),                                        // BitsPerSample
+		tiffEntry(259, 3, 1, 1),                                         // Compression = none
+		tiffEntry(262, 3, 1, 1),                                         // PhotometricInterpretation = BlackIsZero
+		tiffEntry(273, 4, 1, uint32(pixelOffset)),                       // StripOffsets
+		tiffEntry(277, 3, 1, 1),                                         // SamplesPerPixel
+		tiffEntry(278, 4, 1, uint32(nlat)),                              // RowsPerStrip
+		tiffEntry(279, 4, 1, uint32(len(pixels))),                       // StripByteCounts
+		tiffEntry(339, 3, 1, 3),                                         // SampleFormat = IEEE float
+		tiffEntry(33550, 12, 3, uint32(pixelScaleOffset)),               // ModelPixelScaleTag
+		tiffEntry(33922, 12, 6, uint32(tiepointOffset)),                 // ModelTiepointTag
+		tiffEntry(34735, 3, uint32(len(geoKeys)), uint32(geoKeyOffset)), // GeoKeyDirectoryTag
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte{'I', 'I', 42, 0}) // little-endian TIFF magic
+	writeUint32(&buf, uint32(ifdOffset))
+	buf.Write(pixels)
+	buf.Write(geoKeyBytes)
+	buf.Write(pixelScaleBytes)
+	buf.Write(tiepointBytes)
+	writeUint16(&buf, uint16(len(entries)))
+	for _, e := range entries {
+		buf.Write(e)
+	}
+	writeUint32(&buf, 0) // no next IFD
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func tiffEntry(tag, typ uint16, count, value uint32) []byte {
+	b := make([]byte, 12)
+	binary.LittleEndian.PutUint16(b[0:], tag)
+	binary.LittleEndian.PutUint16(b[2:], typ)
+	binary.LittleEndian.PutUint32(b[4:], count)
+	binary.LittleEndian.PutUint32(b[8:], value)
+	return b
+}
+
+func writeUint16(buf *bytes.Buffer, v uint16) {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], v)
+	buf.Write(b[:])
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	buf.Write(b[:])
+}
+
+func float64sToBytes(vals []float64) []byte {
+	out := make([]byte, len(vals)*8)
+	for i, v := range vals {
+		binary.LittleEndian.PutUint64(out[i*8:], math.Float64bits(v))
+	}
+	return out
+}
+
+// ----- GeoJSON contours (marching squares) -----
+
+type geoPoint struct{ Lon, Lat float64 }
+type geoSegment struct{ A, B geoPoint }
+
+// contourPolygons traces the threshold isoline through g with marching
+// squares and stitches the resulting edge segments into closed rings.
+func contourPolygons(g *AreaGrid, threshold float64) [][]geoPoint {
+	segs := marchingSquaresSegments(g, threshold)
+	return stitchRings(segs)
+}
+
+// marchingSquaresSegments classifies each grid cell's four corners against
+// threshold into a 4-bit index (bit order NW, NE, SE, SW) and looks up the
+// matching edge segment(s) in the standard 16-case table, interpolating
+// each endpoint linearly along the cell edge. Longitude is cyclic (see
+// AreaGrid), so j's "east" neighbour wraps via modulo, closing the seam at
+// +-180 instead of leaving it as an open boundary.
+func marchingSquaresSegments(g *AreaGrid, threshold float64) []geoSegment {
+	var segs []geoSegment
+	step := g.lonStep()
+
+	for i := 0; i < g.NLat-1; i++ {
+		y0, y1 := g.latAt(i), g.latAt(i+1)
+		for j := 0; j < g.NLon; j++ {
+			jn := (j + 1) % g.NLon
+			tl, tr := g.Values[i][j], g.Values[i][jn]
+			bl, br := g.Values[i+1][j], g.Values[i+1][jn]
+
+			idx := 0
+			if tl >= threshold {
+				idx |= 8
+			}
+			if tr >= threshold {
+				idx |= 4
+			}
+			if br >= threshold {
+				idx |= 2
+			}
+			if bl >= threshold {
+				idx |= 1
+			}
+			if idx == 0 || idx == 15 {
+				continue
+			}
+
+			x0 := g.lonAt(j)
+			x1 := x0 + step
+
+			top := geoPoint{lerp(x0, x1, tl, tr, threshold), y0}
+			bottom := geoPoint{lerp(x0, x1, bl, br, threshold), y1}
+			left := geoPoint{x0, lerp(y0, y1, tl, bl, threshold)}
+			right := geoPoint{x1, lerp(y0, y1, tr, br, threshold)}
+			avg := (tl + tr + bl + br) / 4
+
+			switch idx {
+			case 1, 14:
+				segs = append(segs, geoSegment{left, bottom})
+			case 2, 13:
+				segs = append(segs, geoSegment{bottom, right})
+			case 3, 12:
+				segs = append(segs, geoSegment{left, right})
+			case 4, 11:
+				segs = append(segs, geoSegment{top, right})
+			case 6, 9:
+				segs = append(segs, geoSegment{top, bottom})
+			case 7, 8:
+				segs = append(segs, geoSegment{top, left})
+			case 5: // saddle: NE+SW high
+				if avg >= threshold {
+					// Center is high too: NE and SW are one connected region
+					// through the middle, leaving NW and SE as the two
+					// isolated low corners, each closed off on its own.
+					segs = append(segs, geoSegment{top, left}, geoSegment{right, bottom})
+				} else {
+					segs = append(segs, geoSegment{top, right}, geoSegment{left, bottom})
+				}
+			case 10: // saddle: NW+SE high
+				if avg >= threshold {
+					// NW and SE connect through the high center, leaving NE
+					// and SW as the two isolated low corners.
+					segs = append(segs, geoSegment{top, right}, geoSegment{left, bottom})
+				} else {
+					segs = append(segs, geoSegment{top, left}, geoSegment{right, bottom})
+				}
+			}
+		}
+	}
+	return segs
+}
+
+func lerp(a, b, va, vb, threshold float64) float64 {
+	if vb == va {
+		return a
+	}
+	return a + (threshold-va)/(vb-va)*(b-a)
+}
+
+// stitchRings greedily walks shared endpoints to join marching-squares
+// segments into closed rings. Segments that never close (grid boundary
+// artifacts at the poles) are dropped.
+func stitchRings(segs []geoSegment) [][]geoPoint {
+	key := func(p geoPoint) string { return fmt.Sprintf("%.6f,%.6f", p.Lon, p.Lat) }
+
+	adjacency := map[string][]int{}
+	for i, s := range segs {
+		adjacency[key(s.A)] = append(adjacency[key(s.A)], i)
+		adjacency[key(s.B)] = append(adjacency[key(s.B)], i)
+	}
+
+	used := make([]bool, len(segs))
+	var rings [][]geoPoint
+	for start := range segs {
+		if used[start] {
+			continue
+		}
+		used[start] = true
+		ring := []geoPoint{segs[start].A, segs[start].B}
+		cur := segs[start].B
+
+		for {
+			next := -1
+			for _, idx := range adjacency[key(cur)] {
+				if !used[idx] {
+					next = idx
+					break
+				}
+			}
+			if next == -1 {
+				break
+			}
+			used[next] = true
+			s := segs[next]
+			if key(s.A) == key(cur) {
+				cur = s.B
+			} else {
+				cur = s.A
+			}
+			ring = append(ring, cur)
+			if key(cur) == key(ring[0]) {
+				break
+			}
+		}
+
+		if len(ring) >= 4 && key(ring[0]) == key(ring[len(ring)-1]) {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// writeGeoJSONContours writes one Polygon Feature per closed ring found at
+// each threshold, tagged with its rel_threshold property.
+func writeGeoJSONContours(path string, g *AreaGrid, thresholds []float64) error {
+	type geometry struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string         `json:"type"`
+		Properties map[string]any `json:"properties"`
+		Geometry   geometry       `json:"geometry"`
+	}
+	type featureCollection struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}
+
+	fc := featureCollection{Type: "FeatureCollection", Features: []feature{}}
+	for _, thr := range thresholds {
+		for _, ring := range contourPolygons(g, thr) {
+			coords := make([][2]float64, len(ring))
+			for i, p := range ring {
+				coords[i] = [2]float64{p.Lon, p.Lat}
+			}
+			fc.Features = append(fc.Features, feature{
+				Type:       "Feature",
+				Properties: map[string]any{"rel_threshold": thr},
+				Geometry:   geometry{Type: "Polygon", Coordinates: [][][2]float64{coords}},
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(fc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// ===================== Progress event sink =====================
+
+// EventSink reports the lifecycle of one (year, month, freq) prediction.
+// textSink mimics the historical "Progress N/total ... Finished" lines;
+// jsonSink emits newline-delimited JSON so a caller can tail the output
+// without screen-scraping (e.g. a CI job parsing stdout).
+type EventSink interface {
+	Start(predID string, year, month int, freq string)
+	Finish(predID string, year, month int, freq string, dur time.Duration)
+	Error(predID string, year, month int, freq string, err error)
+}
+
+func newEventSink(format string, total int) (EventSink, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		return &textSink{total: total}, nil
+	case "json":
+		return &jsonSink{}, nil
+	default:
+		return nil, fmt.Errorf("unknown --log-format %q (want text or json)", format)
+	}
+}
+
+type textSink struct {
+	mu        sync.Mutex
+	completed int
+	total     int
+}
+
+func (s *textSink) Start(predID string, year, month int, freq string) {}
+
+func (s *textSink) Finish(predID string, year, month int, freq string, dur time.Duration) {
+	s.mu.Lock()
+	s.completed++
+	n := s.completed
+	s.mu.Unlock()
+	fmt.Printf("Progress %d/%d ... Finished %s MHz\n", n, s.total, freq)
+}
+
+func (s *textSink) Error(predID string, year, month int, freq string, err error) {
+	s.mu.Lock()
+	s.completed++
+	n := s.completed
+	s.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "Progress %d/%d ... ERROR %s MHz: %v\n", n, s.total, freq, err)
+}
+
+type jsonSink struct {
+	mu sync.Mutex
+}
+
+type jsonEvent struct {
+	Event      string `json:"event"`
+	PredID     string `json:"prediction_id,omitempty"`
+	Year       int    `json:"year,omitempty"`
+	Month      int    `json:"month,omitempty"`
+	Freq       string `json:"freq,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (s *jsonSink) emit(e jsonEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, _ := json.Marshal(e)
+	fmt.Println(string(b))
+}
+
+func (s *jsonSink) Start(predID string, year, month int, freq string) {
+	s.emit(jsonEvent{Event: "start", PredID: predID, Year: year, Month: month, Freq: freq})
+}
+
+func (s *jsonSink) Finish(predID string, year, month int, freq string, dur time.Duration) {
+	s.emit(jsonEvent{Event: "finish", PredID: predID, Year: year, Month: month, Freq: freq, DurationMS: dur.Milliseconds()})
+}
+
+func (s *jsonSink) Error(predID string, year, month int, freq string, err error) {
+	s.emit(jsonEvent{Event: "error", PredID: predID, Year: year, Month: month, Freq: freq, Error: err.Error()})
+}
+
+// ===================== Checkpoints (resumable runs) =====================
+
+// Checkpoint records which (year, month, freq) tuples have already
+// completed for a prediction ID, so a restarted run (--resume <id>) can
+// skip work a crashed earlier run already finished. Safe for concurrent
+// use by worker goroutines; writes are atomic (temp file + rename) so a
+// crash mid-write never corrupts the file on disk.
+type Checkpoint struct {
+	mu     sync.Mutex
+	path   string
+	PredID string          `json:"prediction_id"`
+	Done   map[string]bool `json:"done"`
+}
+
+// loadCheckpoint reads an existing checkpoint file, if any. A missing
+// file is not an error: it just means nothing has completed yet. path=""
+// disables checkpointing entirely (Has/MarkDone become no-ops).
+func loadCheckpoint(path, predID string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, PredID: predID, Done: map[string]bool{}}
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var loaded Checkpoint
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	if loaded.PredID != "" && loaded.PredID != predID {
+		return nil, fmt.Errorf("checkpoint %s belongs to prediction %s, not %s (use --resume %s)", path, loaded.PredID, predID, loaded.PredID)
+	}
+	if loaded.Done != nil {
+		c.Done = loaded.Done
+	}
+	return c, nil
+}
+
+// checkpointKey includes site so that a manifest with several tx_sites
+// doesn't treat site B's (year, month, freq) as already done just because
+// site A's identical tuple completed first.
+func checkpointKey(site string, year, month int, freq string) string {
+	return fmt.Sprintf("%s|%d-%02d-%s", site, year, month, freq)
+}
+
+// Has reports whether (site, year, month, freq) already completed in a prior run.
+func (c *Checkpoint) Has(site string, year, month int, freq string) bool {
+	if c == nil || c.path == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Done[checkpointKey(site, year, month, freq)]
+}
+
+// MarkDone atomically persists that (site, year, month, freq) has completed.
+func (c *Checkpoint) MarkDone(site string, year, month int, freq string) error {
+	if c == nil || c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Done[checkpointKey(site, year, month, freq)] = true
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+type itemOutcome int
+
+const (
+	itemSucceeded itemOutcome = iota
+	itemFailed
+	itemSkipped
+)
+
+// runOneItem is the unit of work shared by the interactive loop and
+// manifest mode: skip already-checkpointed tuples, report start/finish/
+// error through sink, and checkpoint on success. site namespaces both the
+// output directory and the checkpoint key so a manifest driving several
+// tx_sites doesn't collide site A's and site B's runs for the same
+// (year, month, freq); it's "" for the single-site interactive flow.
+func runOneItem(cfg Config, predRoot, predID, site string, year, month int, hours []int, monthList, ssnList, hourList, tLat, tLon, txName string, ssn int, freq string, sink EventSink, ckpt *Checkpoint) (itemOutcome, error) {
+	if ckpt.Has(site, year, month, freq) {
+		return itemSkipped, nil
+	}
+	sink.Start(predID, year, month, freq)
+	t0 := time.Now()
+	err := makeVOACAPPrediction(cfg, predRoot, site, year, month, hours, monthList, ssnList, hourList, tLat, tLon, txName, ssn, freq)
+	if err != nil {
+		sink.Error(predID, year, month, freq, err)
+		return itemFailed, err
+	}
+	sink.Finish(predID, year, month, freq, time.Since(t0))
+	if err := ckpt.MarkDone(site, year, month, freq); err != nil {
+		sink.Error(predID, year, month, freq, fmt.Errorf("writing checkpoint: %w", err))
+	}
+	return itemSucceeded, nil
+}
+
+// ===================== Manifest mode =====================
+
+// RunManifest replaces the interactive prompts with a single declarative
+// file. Years/Months/StartHour/TimeRange mirror the ask* prompts; SSNByMonth
+// and AntennaOverrides are optional and only apply where a key matches.
+type RunManifest struct {
+	Years     []int    `json:"years" yaml:"years"`
+	Months    []int    `json:"months" yaml:"months"`
+	StartHour int      `json:"start_hour" yaml:"start_hour"`
+	TimeRange int      `json:"time_range" yaml:"time_range"`
+	TxSites   []TxSite `json:"tx_sites" yaml:"tx_sites"`
+
+	// SSNByMonth overrides the SSN lookup, keyed "YYYY-MM".
+	SSNByMonth map[string]int `json:"ssn_by_month" yaml:"ssn_by_month"`
+
+	// AntennaOverrides overrides cfg.TxAnt/RxAnt, keyed by frequency string
+	// (e.g. "14.100"), same convention as antForFreq.
+	AntennaOverrides map[string]AntennaOverride `json:"antenna_overrides" yaml:"antenna_overrides"`
+}
+
+// TxSite optionally overrides the [default] txlat/txlon from voacap.ini so
+// one manifest can drive several transmit sites in a single run.
+type TxSite struct {
+	Name string  `json:"name" yaml:"name"`
+	Lat  float64 `json:"lat" yaml:"lat"`
+	Lon  float64 `json:"lon" yaml:"lon"`
+}
+
+type AntennaOverride struct {
+	TxAnt string `json:"tx_ant" yaml:"tx_ant"`
+	RxAnt string `json:"rx_ant" yaml:"rx_ant"`
+}
+
+var reUnsafePathChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// siteDirName returns the path segment used to namespace a TxSite's output
+// under predRoot, so that two tx_sites entries never write to the same
+// runDir/checkpoint key for a given (year, month, freq). Prefers the
+// manifest's site.Name (sanitized for use as a single path component);
+// falls back to a positional "site<N>" name when Name is blank.
+func siteDirName(site TxSite, idx int) string {
+	name := reUnsafePathChars.ReplaceAllString(strings.TrimSpace(site.Name), "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return fmt.Sprintf("site%d", idx)
+	}
+	return name
+}
+
+// validSSN reports whether ssn is in 0-300, the range the interactive SSN
+// prompt also enforces before the value goes on the deck's Ssns line;
+// getSSN's -1 "not found" result and an out-of-range ssn_by_month value
+// both fail it.
+func validSSN(ssn int) bool {
+	return ssn >= 0 && ssn <= 300
+}
+
+// manifestSummary is the machine-readable result printed to stdout at the
+// end of a manifest run, one JSON object, so CI can parse it without
+// scraping the human progress lines.
+type manifestSummary struct {
+	PredictionID string   `json:"prediction_id"`
+	OutputDir    string   `json:"output_dir"`
+	Total        int      `json:"total"`
+	Succeeded    int      `json:"succeeded"`
+	Skipped      int      `json:"skipped"`
+	Failed       int      `json:"failed"`
+	Errors       []string `json:"errors,omitempty"`
+	ElapsedMS    int64    `json:"duration_ms"`
+}
+
+func loadManifest(path string) (*RunManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &RunManifest{}
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".json":
+		if err := json.Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("parsing JSON manifest: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := parseManifestYAML(data, m); err != nil {
+			return nil, fmt.Errorf("parsing YAML manifest: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized manifest extension %q (want .json, .yaml or .yml)", ext)
+	}
+	if len(m.Years) == 0 {
+		return nil, errors.New("manifest: years must not be empty")
+	}
+	if len(m.Months) == 0 {
+		return nil, errors.New("manifest: months must not be empty")
+	}
+	if m.TimeRange <= 0 {
+		m.TimeRange = 24
+	}
+	if len(m.TxSites) == 0 {
+		m.TxSites = []TxSite{{Name: "default"}}
+	}
+	return m, nil
+}
+
+// parseManifestYAML supports the flat subset of YAML this manifest actually
+// needs: scalar "key: value" pairs, "key:" blocks followed by "  - item"
+// sequences (plain or one-line "  - {a: 1, b: 2}" maps), and two levels of
+// nesting. It deliberately does not attempt general YAML; voacap.ini's own
+// readINI takes the same pragmatic approach for its file format.
+func parseManifestYAML(data []byte, m *RunManifest) error {
+	lines := strings.Split(string(data), "\n")
+	var section string
+	for i := 0; i < len(lines); i++ {
+		line := stripYAMLComment(lines[i])
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		trimmed := strings.TrimSpace(line)
+
+		if indent == 0 {
+			kv := strings.SplitN(trimmed, ":", 2)
+			key := strings.TrimSpace(kv[0])
+			val := ""
+			if len(kv) == 2 {
+				val = strings.TrimSpace(kv[1])
+			}
+			section = key
+			if val == "" {
+				continue // block follows on subsequent indented lines
+			}
+			if err := assignManifestScalar(m, key, val); err != nil {
+				return err
+			}
+			section = ""
+			continue
+		}
+
+		// Indented line: either a "- value" / "- {a: b, c: d}" list item or
+		// a "key: value" pair under a map block (ssn_by_month,
+		// antenna_overrides). Under tx_sites every indented line is one
+		// whole site ("- {name: ..., lat: ..., lon: ...}" or a bare
+		// "- name: ..."); a site's fields cannot be split across lines.
+		item := strings.TrimPrefix(trimmed, "- ")
+		switch section {
+		case "years":
+			if v, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+				m.Years = append(m.Years, v)
+			}
+		case "months":
+			if v, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+				m.Months = append(m.Months, v)
+			}
+		case "tx_sites":
+			site := parseYAMLInlineMap(item)
+			ts := TxSite{Name: site["name"]}
+			ts.Lat, _ = strconv.ParseFloat(site["lat"], 64)
+			ts.Lon, _ = strconv.ParseFloat(site["lon"], 64)
+			m.TxSites = append(m.TxSites, ts)
+		case "ssn_by_month":
+			kv := strings.SplitN(trimmed, ":", 2)
+			if len(kv) == 2 {
+				if m.SSNByMonth == nil {
+					m.SSNByMonth = map[string]int{}
+				}
+				if v, err := strconv.Atoi(strings.TrimSpace(kv[1])); err == nil {
+					m.SSNByMonth[strings.TrimSpace(kv[0])] = v
+				}
+			}
+		case "antenna_overrides":
+			kv := strings.SplitN(trimmed, ":", 2)
+			if len(kv) == 2 {
+				freq := strings.TrimSpace(kv[0])
+				ov := parseYAMLInlineMap(strings.TrimSpace(kv[1]))
+				if m.AntennaOverrides == nil {
+					m.AntennaOverrides = map[string]AntennaOverride{}
+				}
+				m.AntennaOverrides[freq] = AntennaOverride{TxAnt: ov["tx_ant"], RxAnt: ov["rx_ant"]}
+			}
+		}
+	}
+	return nil
+}
+
+func assignManifestScalar(m *RunManifest, key, val string) error {
+	switch key {
+	case "start_hour":
+		v, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("manifest: start_hour: %w", err)
+		}
+		m.StartHour = v
+	case "time_range":
+		v, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("manifest: time_range: %w", err)
+		}
+		m.TimeRange = v
+	}
+	return nil
+}
+
+func stripYAMLComment(line string) string {
+	if i := strings.Index(line, "#"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// parseYAMLInlineMap parses "{a: 1, b: 2}" or "a: 1" (bare, no braces) into
+// a string map; used for the small nested records this manifest allows.
+func parseYAMLInlineMap(s string) map[string]string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "{")
+	s = strings.TrimSuffix(s, "}")
+	out := map[string]string{}
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		out[strings.TrimSpace(kv[0])] = strings.Trim(strings.TrimSpace(kv[1]), `"'`)
+	}
+	return out
+}
+
+// runManifest drives a full batch from a RunManifest instead of the
+// interactive prompts, then exits non-zero if anything failed.
+func runManifest(cfg Config, manifestPath string) {
+	m, err := loadManifest(manifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: loading manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Apply antenna overrides up front; antForFreq falls back to cfg's INI
+	// values for any frequency the manifest doesn't mention.
+	for freq, ov := range m.AntennaOverrides {
+		if ov.TxAnt != "" {
+			cfg.TxAnt[freq] = ov.TxAnt
+		}
+		if ov.RxAnt != "" {
+			cfg.RxAnt[freq] = ov.RxAnt
+		}
+	}
+
+	sort.Ints(m.Years)
+	sort.Ints(m.Months)
+	hours := make([]int, m.TimeRange)
+	for i := range hours {
+		hours[i] = (m.StartHour + i) % 24
+	}
+
+	preID := randomID8()
+	if strings.TrimSpace(*resumeFlag) != "" {
+		preID = strings.TrimSpace(*resumeFlag)
+	}
+	predRoot := filepath.Join(basePredDir, preID)
+	if err := os.MkdirAll(predRoot, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: cannot create base prediction directory %s: %v\n", predRoot, err)
+		os.Exit(1)
+	}
+
+	ckpt, err := loadCheckpoint(*checkpointFlag, preID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: loading checkpoint: %v\n", err)
+		os.Exit(1)
+	}
+
+	total := len(m.TxSites) * len(m.Years) * len(m.Months) * len(cfg.FList)
+	sink, err := newEventSink(*logFormatFlag, total)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+
+	start := time.Now()
+	maxWorkers := *workersFlag
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+
+	summary := manifestSummary{PredictionID: preID, OutputDir: predRoot}
+	var mu sync.Mutex
+
+	for siteIdx, site := range m.TxSites {
+		siteCfg := cfg
+		if site.Lat != 0 || site.Lon != 0 {
+			siteCfg.TxLat, siteCfg.TxLon = site.Lat, site.Lon
+		}
+		txName := latlon2loc(siteCfg.TxLat, siteCfg.TxLon, 3)
+		tLat := fmt.Sprintf("%6.2f", siteCfg.TxLat)
+		tLon := fmt.Sprintf("%7.2f", siteCfg.TxLon)
+		siteDir := siteDirName(site, siteIdx)
+
+		for _, year := range m.Years {
+			for _, month := range m.Months {
+				ssn, ok := m.SSNByMonth[fmt.Sprintf("%04d-%02d", year, month)]
+				if !ok {
+					ssn = getSSN(ssnFile, year, month)
+				}
+				if !validSSN(ssn) {
+					fmt.Fprintf(os.Stderr, "ERROR: no valid SSN for %04d-%02d and none in manifest; skipping\n", year, month)
+					mu.Lock()
+					for range siteCfg.FList {
+						summary.Total++
+						summary.Failed++
+						summary.Errors = append(summary.Errors, fmt.Sprintf("site %s: no valid SSN for %04d-%02d", siteDir, year, month))
+					}
+					mu.Unlock()
+					continue
+				}
+				monthList := "Months   :" + repeatFloat(float64(month), len(hours), 7, 2)
+				ssnList := "Ssns     :" + repeatInt(ssn, len(hours), 7)
+				hourList := "Hours    :" + joinInts(hours, 7)
+
+				var wg sync.WaitGroup
+				sem := make(chan struct{}, maxWorkers)
+				for _, f := range siteCfg.FList {
+					freq := f
+					wg.Add(1)
+					sem <- struct{}{}
+					go func() {
+						defer wg.Done()
+						defer func() { <-sem }()
+						outcome, err := runOneItem(siteCfg, predRoot, preID, siteDir, year, month, hours, monthList, ssnList, hourList, tLat, tLon, txName, ssn, freq, sink, ckpt)
+						mu.Lock()
+						summary.Total++
+						switch outcome {
+						case itemSucceeded:
+							summary.Succeeded++
+						case itemSkipped:
+							summary.Skipped++
+						case itemFailed:
+							summary.Failed++
+							summary.Errors = append(summary.Errors, err.Error())
+						}
+						mu.Unlock()
+					}()
+				}
+				wg.Wait()
+			}
+		}
+	}
+
+	summary.ElapsedMS = time.Since(start).Milliseconds()
+	out, _ := json.Marshal(summary)
+	fmt.Println(string(out))
+
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// ===================== INI parsing =====================
+
+func readINI(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+
+	sec := "default"
+	c := Config{
+		TxAnt: make(map[string]string),
+		RxAnt: make(map[string]string),
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, err
+		}
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if errors.Is(err, nil) {
+				continue
+			}
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sec = strings.ToLower(strings.Trim(line, "[]"))
+		} else if kv := strings.SplitN(line, "=", 2); len(kv) == 2 {
+			k := strings.ToLower(strings.TrimSpace(kv[0]))
+			v := strings.TrimSpace(kv[1])
+			switch sec {
+			case "default":
+				switch k {
+				case "txlat":
+					c.TxLat, _ = strconv.ParseFloat(v, 64)
+				case "txlon":
+					c.TxLon, _ = strconv.ParseFloat(v, 64)
+				case "power":
+					c.Power, _ = strconv.ParseFloat(v, 64)
+				case "mode":
+					c.Mode, _ = strconv.Atoi(v)
+				case "es":
+					c.Es, _ = strconv.ParseFloat(v, 64)
+				case "method":
+					c.Method, _ = strconv.Atoi(v)
+				case "mintoa":
+					c.MinToa, _ = strconv.ParseFloat(v, 64)
+				case "noise":
+					c.Noise, _ = strconv.Atoi(v)
+				case "gridsize":
+					c.GridSize, _ = strconv.Atoi(v)
+				case "path":
+					c.PathFlag = v
+				}
+			case "frequency":
+				if k == "flist" {
+					parts := strings.Fields(v)
+					c.FList = make([]string, 0, len(parts))
+					for _, p := range parts {
+						if p != "" {
+							c.FList = append(c.FList, p)
+						}
+					}
+				}
+			case "antenna":
+				// Expect keys like txant20, rxant20, etc.
+				if strings.HasPrefix(k, "txant") {
+					c.TxAnt[antKeyFrom(k)] = v
+				} else if strings.HasPrefix(k, "rxant") {
+					c.RxAnt[antKeyFrom(k)] = v
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	// Basic validation
+	if len(c.FList) == 0 {
+		return Config{}, fmt.Errorf("frequency.flist is empty")
+	}
+	return c, nil
+}
+
+func antKeyFrom(k string) string {
+	// Map INI keys to exact frequency strings used in Python mapping
+	// txant80 -> "3.500", txant60 -> "5.300", txant40 -> "7.100",
+	// txant30 -> "10.100", txant20 -> "14.100", txant17 -> "18.100",
+	// txant15 -> "21.200", txant12 -> "24.900", txant10 -> "28.200"
+	switch {
+	case strings.HasSuffix(k, "80"):
+		return "3.500"
+	case strings.HasSuffix(k, "60"):
+		return "5.300"
+	case strings.HasSuffix(k, "40"):
+		return "7.100"
+	case strings.HasSuffix(k, "30"):
+		return "10.100"
+	case strings.HasSuffix(k, "20"):
+		return "14.100"
+	case strings.HasSuffix(k, "17"):
+		return "18.100"
+	case strings.HasSuffix(k, "15"):
+		return "21.200"
+	case strings.HasSuffix(k, "12"):
+		return "24.900"
+	case strings.HasSuffix(k, "10"):
+		return "28.200"
+	default:
+		return "28.200"
+	}
+}
+
+// ===================== SSN =====================
+
+func getSSN(path string, year, month int) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1
+	}
+	defer f.Close()
+
+	target := fmt.Sprintf("%d %02d", year, month)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.Contains(line, target) {
+			parts := strings.Fields(line)
+			if len(parts) >= 5 {
+				val := parts[4]
+				if f64, err := strconv.ParseFloat(val, 64); err == nil {
+					// Reduce forecasted future or current year slightly as in Python
+					if year >= time.Now().UTC().Year() {
+						f64 = roundHalfUp(f64*0.7, 0)
+					}
+					return int(f64)
+				}
+			}
+			break
+		}
+	}
+	return -1
+}
+
+func roundHalfUp(n float64, decimals int) float64 {
+	mult := math.Pow(10, float64(decimals))
+	return math.Floor(n*mult+0.5) / mult
+}
+
+// ===================== Helpers =====================
+
+func repeatFloat(v float64, count, width, prec int) string {
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		b.WriteString(fmt.Sprintf("%*.*f", width, prec, v))
+	}
+	return b.String()
+}
+
+func repeatInt(v, count, width int) string {
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		b.WriteString(fmt.Sprintf("%*d", width, v))
+	}
+	return b.String()
+}
+
+func repeatString(s string, count, width int) string {
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		b.WriteString(fmt.Sprintf("%*s", width, s))
+	}
+	return b.String()
+}
+
+func joinInts(vals []int, width int) string {
+	var b strings.Builder
+	for _, v := range vals {
+		b.WriteString(fmt.Sprintf("%*d", width, v))
+	}
+	return b.String()
+}
+
+func ask(prompt string) string {
+	fmt.Print(prompt)
+	s, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(s)
+}
+
+func askYears(prompt string) []int {
+	for {
+		raw := ask(prompt)
+		fields := strings.Fields(raw)
+		var ys []int
+		for _, f := range fields {
+			if v, err := strconv.Atoi(f); err == nil && v >= 2021 && v <= 2100 {
+				ys = append(ys, v)
+			}
+		}
+		ys = uniqueInts(ys)
+		sort.Ints(ys)
+		if len(ys) > 0 {
+			return ys
+		}
+	}
+}
+
+func askMonths(prompt string) []int {
+	for {
+		raw := ask(prompt)
+		fields := strings.Fields(raw)
+		var ms []int
+		for _, f := range fields {
+			if v, err := strconv.Atoi(f); err == nil && v >= 1 && v <= 12 {
+				ms = append(ms, v)
+			}
+		}
+		ms = uniqueInts(ms)
+		sort.Ints(ms)
+		if len(ms) > 0 {
+			return ms
+		}
+	}
+}
+
+func askIntInRange(prompt string, lo, hi int) int {
+	for {
+		raw := ask(prompt)
+		if v, err := strconv.Atoi(strings.TrimSpace(raw)); err == nil && v >= lo && v <= hi {
+			return v
+		}
+	}
+}
+
+func uniqueInts(in []int) []int {
+	seen := map[int]struct{}{}
+	out := make([]int, 0, len(in))
+	for _, v := range in {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Maidenhead grid locator (precision fields: 3 -> 6 chars)
+func latlon2loc(lat, lon float64, precision int) string {
+	// Mirror Python logic
+	A := int('A')
+	a0 := divmod(lon+180, 20)
+	b0 := divmod(lat+90, 10)
+	as := string(rune(A+int(a0.quot))) + string(rune(A+int(b0.quot)))
+	lonR := a0.rem / 2.0
+	latR := b0.rem
+	i := 1
+	for i < precision {
+		i++
+		a := divmod(lonR, 1)
+		b := divmod(latR, 1)
+		if i%2 == 0 {
+			as += fmt.Sprintf("%d%d", int(a.quot), int(b.quot))
+			lonR = 24 * a.rem
+			latR = 24 * b.rem
+		} else {
+			as += string(rune(A+int(a.quot))) + string(rune(A+int(b.quot)))
+			lonR = 10 * a.rem
+			latR = 10 * b.rem
+		}
+	}
+	if len(as) >= 6 {
+		as = as[:4] + strings.ToLower(as[4:6]) + as[6:]
+	}
+	return strings.ToUpper(as)
+}
+
+type div struct {
+	quot float64
+	rem  float64
+}
+
+func divmod(x, y float64) div {
+	q := math.Floor(x / y)
+	r := x - q*y
+	return div{q, r}
+}
+
+func randomID8() string {
+	rand.Seed(time.Now().UnixNano())
+	return fmt.Sprintf("%08x", rand.Uint32())
+}
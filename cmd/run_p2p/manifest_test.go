@@ -0,0 +1,117 @@
+package main
+
+import "testing"
+
+// TestParseManifestYAML covers the flat YAML subset loadManifest relies
+// on: scalars, a plain sequence, an inline-map sequence (tx_sites), and
+// nested "key: value" blocks (ssn_by_month, antenna_overrides).
+func TestParseManifestYAML(t *testing.T) {
+	src := `
+years:
+  - 2025
+  - 2026
+months:
+  - 1
+  - 7
+start_hour: 0
+time_range: 24
+tx_sites:
+  - {name: OH6BG, lat: 60.17, lon: 24.94}
+  - name: W1AW
+ssn_by_month:
+  2025-01: 80
+  2026-07: 120
+antenna_overrides:
+  14.100: {tx_ant: log4, rx_ant: dipole}
+`
+	m := &RunManifest{}
+	if err := parseManifestYAML([]byte(src), m); err != nil {
+		t.Fatalf("parseManifestYAML: %v", err)
+	}
+
+	if got := m.Years; len(got) != 2 || got[0] != 2025 || got[1] != 2026 {
+		t.Errorf("Years = %v, want [2025 2026]", got)
+	}
+	if got := m.Months; len(got) != 2 || got[0] != 1 || got[1] != 7 {
+		t.Errorf("Months = %v, want [1 7]", got)
+	}
+	if m.TimeRange != 24 {
+		t.Errorf("TimeRange = %d, want 24", m.TimeRange)
+	}
+	if len(m.TxSites) != 2 {
+		t.Fatalf("got %d TxSites, want 2", len(m.TxSites))
+	}
+	if m.TxSites[0].Name != "OH6BG" || m.TxSites[0].Lat != 60.17 || m.TxSites[0].Lon != 24.94 {
+		t.Errorf("TxSites[0] = %+v, want OH6BG at 60.17,24.94", m.TxSites[0])
+	}
+	if m.TxSites[1].Name != "W1AW" {
+		t.Errorf("TxSites[1] = %+v, want Name=W1AW", m.TxSites[1])
+	}
+	if m.SSNByMonth["2025-01"] != 80 || m.SSNByMonth["2026-07"] != 120 {
+		t.Errorf("SSNByMonth = %v, want {2025-01:80, 2026-07:120}", m.SSNByMonth)
+	}
+	ov, ok := m.AntennaOverrides["14.100"]
+	if !ok || ov.TxAnt != "log4" || ov.RxAnt != "dipole" {
+		t.Errorf("AntennaOverrides[14.100] = %+v, ok=%v, want {log4 dipole}", ov, ok)
+	}
+}
+
+// TestParseManifestYAMLStripsComments checks stripYAMLComment is applied
+// before a line is otherwise parsed.
+func TestParseManifestYAMLStripsComments(t *testing.T) {
+	src := `
+years:
+  - 2025 # this year
+start_hour: 6 # UTC
+`
+	m := &RunManifest{}
+	if err := parseManifestYAML([]byte(src), m); err != nil {
+		t.Fatalf("parseManifestYAML: %v", err)
+	}
+	if len(m.Years) != 1 || m.Years[0] != 2025 {
+		t.Errorf("Years = %v, want [2025]", m.Years)
+	}
+	if m.StartHour != 6 {
+		t.Errorf("StartHour = %d, want 6", m.StartHour)
+	}
+}
+
+// TestValidSSN covers the boundary runManifest relies on to turn a
+// missing/out-of-range SSN into a recorded failure rather than a silent
+// skip: 0 and 300 are valid, -1 (getSSN's "not found" sentinel) and 301
+// are not.
+func TestValidSSN(t *testing.T) {
+	cases := []struct {
+		ssn  int
+		want bool
+	}{
+		{-1, false},
+		{0, true},
+		{150, true},
+		{300, true},
+		{301, false},
+	}
+	for _, c := range cases {
+		if got := validSSN(c.ssn); got != c.want {
+			t.Errorf("validSSN(%d) = %v, want %v", c.ssn, got, c.want)
+		}
+	}
+}
+
+func TestSiteDirName(t *testing.T) {
+	cases := []struct {
+		site TxSite
+		idx  int
+		want string
+	}{
+		{TxSite{Name: "OH6BG"}, 0, "OH6BG"},
+		{TxSite{Name: "W1AW / test site"}, 2, "W1AW_test_site"},
+		{TxSite{}, 3, "site3"},
+		{TxSite{Name: "   "}, 5, "site5"},
+	}
+	for _, c := range cases {
+		if got := siteDirName(c.site, c.idx); got != c.want {
+			t.Errorf("siteDirName(%+v, %d) = %q, want %q", c.site, c.idx, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import "testing"
+
+// saddleGrid builds a 2x3 AreaGrid whose (i=0, j=0) cell has the given four
+// corner values. The j=1 and j=2 cells (the latter wrapping back to column
+// 0) span longitudes 1-2 and 2-3, so their segments never coincide with the
+// ones asserted against cell (0,0), which spans longitudes 0-1.
+func saddleGrid(tl, tr, bl, br float64) *AreaGrid {
+	return &AreaGrid{
+		LonMin: 0, LonMax: 3,
+		LatMin: 0, LatMax: 2,
+		NLon: 3, NLat: 2,
+		Values: [][]float64{
+			{tl, tr, tr},
+			{bl, br, br},
+		},
+	}
+}
+
+func hasSegment(segs []geoSegment, a, b geoPoint) bool {
+	for _, s := range segs {
+		if s.A == a && s.B == b {
+			return true
+		}
+	}
+	return false
+}
+
+// TestMarchingSquaresSaddleCase5 covers the idx-5 saddle (NE+SW above
+// threshold). When the cell-center average is also above threshold, NE and
+// SW are one connected region through the middle, so the isoline must
+// isolate the two low corners (NW, SE) individually: {top,left} and
+// {right,bottom}. Below the average, NW and SE are one region and NE/SW
+// are isolated instead: {top,right} and {left,bottom}.
+func TestMarchingSquaresSaddleCase5(t *testing.T) {
+	const threshold = 5.0
+
+	// avg = (0+10+10+0)/4 = 5 >= threshold
+	high := saddleGrid(0, 10, 10, 0)
+	segs := marchingSquaresSegments(high, threshold)
+	top := geoPoint{0.5, 2}
+	bottom := geoPoint{0.5, 0}
+	left := geoPoint{0, 1}
+	right := geoPoint{1, 1}
+	if !hasSegment(segs, top, left) || !hasSegment(segs, right, bottom) {
+		t.Errorf("case 5, avg>=threshold: want segments {top,left} and {right,bottom}, got %+v", segs)
+	}
+	if hasSegment(segs, top, right) || hasSegment(segs, left, bottom) {
+		t.Errorf("case 5, avg>=threshold: unexpectedly got the opposite (isolated-corner) pairing: %+v", segs)
+	}
+
+	// avg = (0+6+6+0)/4 = 3 < threshold
+	low := saddleGrid(0, 6, 6, 0)
+	segs = marchingSquaresSegments(low, threshold)
+	top = geoPoint{lerp(0, 1, 0, 6, threshold), 2}
+	bottom = geoPoint{lerp(0, 1, 6, 0, threshold), 0}
+	left = geoPoint{0, lerp(2, 0, 0, 6, threshold)}
+	right = geoPoint{1, lerp(2, 0, 6, 0, threshold)}
+	if !hasSegment(segs, top, right) || !hasSegment(segs, left, bottom) {
+		t.Errorf("case 5, avg<threshold: want segments {top,right} and {left,bottom}, got %+v", segs)
+	}
+}
+
+// TestMarchingSquaresSaddleCase10 covers the complementary idx-10 saddle
+// (NW+SE above threshold); the avg comparison flips which pair of corners
+// gets isolated, mirroring TestMarchingSquaresSaddleCase5.
+func TestMarchingSquaresSaddleCase10(t *testing.T) {
+	const threshold = 5.0
+
+	// avg = (10+0+0+10)/4 = 5 >= threshold: NW+SE connect through the
+	// center, leaving NE and SW as the isolated low corners.
+	high := saddleGrid(10, 0, 0, 10)
+	segs := marchingSquaresSegments(high, threshold)
+	top := geoPoint{0.5, 2}
+	bottom := geoPoint{0.5, 0}
+	left := geoPoint{0, 1}
+	right := geoPoint{1, 1}
+	if !hasSegment(segs, top, right) || !hasSegment(segs, left, bottom) {
+		t.Errorf("case 10, avg>=threshold: want segments {top,right} and {left,bottom}, got %+v", segs)
+	}
+	if hasSegment(segs, top, left) || hasSegment(segs, right, bottom) {
+		t.Errorf("case 10, avg>=threshold: unexpectedly got the opposite (isolated-corner) pairing: %+v", segs)
+	}
+
+	// avg = (6+0+0+6)/4 = 3 < threshold
+	low := saddleGrid(6, 0, 0, 6)
+	segs = marchingSquaresSegments(low, threshold)
+	top = geoPoint{lerp(0, 1, 6, 0, threshold), 2}
+	bottom = geoPoint{lerp(0, 1, 0, 6, threshold), 0}
+	left = geoPoint{0, lerp(2, 0, 6, 0, threshold)}
+	right = geoPoint{1, lerp(2, 0, 0, 6, threshold)}
+	if !hasSegment(segs, top, left) || !hasSegment(segs, right, bottom) {
+		t.Errorf("case 10, avg<threshold: want segments {top,left} and {right,bottom}, got %+v", segs)
+	}
+}
@@ -0,0 +1,130 @@
+package plotter
+
+import (
+	_ "embed"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed coastlines_lowres.txt
+var coastlinesLowRes string
+
+var (
+	coastlineOnce  sync.Once
+	coastlinePolys [][]geoPoint
+)
+
+type geoPoint struct{ Lon, Lat float64 }
+
+// loadCoastlines parses the embedded low-res coastline asset once per
+// process and caches the result; every Render call shares it read-only.
+func loadCoastlines() [][]geoPoint {
+	coastlineOnce.Do(func() {
+		var polys [][]geoPoint
+		var cur []geoPoint
+		for _, line := range strings.Split(coastlinesLowRes, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				if len(cur) > 0 {
+					polys = append(polys, cur)
+					cur = nil
+				}
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			lon, err1 := strconv.ParseFloat(fields[0], 64)
+			lat, err2 := strconv.ParseFloat(fields[1], 64)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			cur = append(cur, geoPoint{Lon: lon, Lat: lat})
+		}
+		if len(cur) > 0 {
+			polys = append(polys, cur)
+		}
+		coastlinePolys = polys
+	})
+	return coastlinePolys
+}
+
+// drawCoastlines strokes each coastline polyline onto img by projecting
+// its points and connecting consecutive ones with a simple Bresenham line,
+// skipping segments that wrap most of the way around the canvas (a
+// cylindrical projection's antimeridian seam, not a real coastline).
+func drawCoastlines(img *image.RGBA, proj Projection, lonMin, lonMax, centerLat, centerLon float64, width, height int, col color.RGBA) {
+	for _, poly := range loadCoastlines() {
+		for i := 0; i+1 < len(poly); i++ {
+			x0, y0, ok0 := project(proj, poly[i].Lat, poly[i].Lon, lonMin, lonMax, centerLat, centerLon, width, height)
+			x1, y1, ok1 := project(proj, poly[i+1].Lat, poly[i+1].Lon, lonMin, lonMax, centerLat, centerLon, width, height)
+			if !ok0 || !ok1 {
+				continue
+			}
+			if abs(x1-x0) > width/2 {
+				continue // antimeridian seam wrap, not a real edge
+			}
+			drawLine(img, x0, y0, x1, y1, col)
+		}
+	}
+}
+
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	b := img.Bounds()
+	for {
+		if x0 >= b.Min.X && x0 < b.Max.X && y0 >= b.Min.Y && y0 < b.Max.Y {
+			img.SetRGBA(x0, y0, col)
+		}
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawMarker draws a small filled square centered on (lat, lon), used for
+// the TX site.
+func drawMarker(img *image.RGBA, proj Projection, lat, lon, lonMin, lonMax, centerLat, centerLon float64, width, height int, col color.RGBA, radius int) {
+	cx, cy, ok := project(proj, lat, lon, lonMin, lonMax, centerLat, centerLon, width, height)
+	if !ok {
+		return
+	}
+	b := img.Bounds()
+	for dx := -radius; dx <= radius; dx++ {
+		for dy := -radius; dy <= radius; dy++ {
+			x, y := cx+dx, cy+dy
+			if x >= b.Min.X && x < b.Max.X && y >= b.Min.Y && y < b.Max.Y {
+				img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
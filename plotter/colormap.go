@@ -0,0 +1,84 @@
+package plotter
+
+import "image/color"
+
+// MapType selects which VOACAP parameter to render and which color scale
+// to apply. These mirror the -d flag voaAreaPlot.py accepts and the
+// mapTypes table in cmd/plot_maps: MUF, REL, SNR50, SNR90, SDBW.
+type MapType string
+
+const (
+	MapMUF   MapType = "MUF"
+	MapREL   MapType = "REL"
+	MapSNR50 MapType = "SNR50"
+	MapSNR90 MapType = "SNR90"
+	MapSDBW  MapType = "SDBW"
+)
+
+// colorScale maps a raw grid value onto a color ramp via linear
+// interpolation between Stops, which are evenly spaced across [Min, Max].
+type colorScale struct {
+	Min, Max float64
+	Stops    []color.RGBA
+}
+
+// scales gives each MapType a plausible value range and a ramp from cold
+// (poor) to hot (good) propagation conditions. These are reasonable
+// defaults rather than a transcription of voaAreaPlot.py's exact breakpoints
+// (which live in Python and aren't available to this package), and can be
+// tuned independently of it now that rendering no longer shells out.
+var scales = map[MapType]colorScale{
+	MapMUF: {Min: 2, Max: 30, Stops: []color.RGBA{
+		{0, 0, 128, 255}, {0, 128, 255, 255}, {0, 200, 0, 255},
+		{255, 255, 0, 255}, {255, 128, 0, 255}, {200, 0, 0, 255},
+	}},
+	MapREL: {Min: 0, Max: 1, Stops: []color.RGBA{
+		{80, 0, 0, 255}, {200, 0, 0, 255}, {255, 165, 0, 255},
+		{255, 255, 0, 255}, {0, 200, 0, 255},
+	}},
+	MapSNR50: {Min: -10, Max: 50, Stops: []color.RGBA{
+		{40, 0, 80, 255}, {0, 0, 200, 255}, {0, 180, 220, 255},
+		{0, 200, 0, 255}, {255, 220, 0, 255}, {220, 0, 0, 255},
+	}},
+	MapSNR90: {Min: -10, Max: 50, Stops: []color.RGBA{
+		{40, 0, 80, 255}, {0, 0, 200, 255}, {0, 180, 220, 255},
+		{0, 200, 0, 255}, {255, 220, 0, 255}, {220, 0, 0, 255},
+	}},
+	MapSDBW: {Min: -160, Max: -60, Stops: []color.RGBA{
+		{0, 0, 0, 255}, {60, 0, 120, 255}, {0, 0, 220, 255},
+		{0, 200, 200, 255}, {0, 220, 0, 255}, {255, 255, 0, 255},
+	}},
+}
+
+// Color maps v onto s's ramp, clamping out-of-range values to the nearest
+// end stop instead of extrapolating.
+func (s colorScale) Color(v float64) color.RGBA {
+	if len(s.Stops) == 1 {
+		return s.Stops[0]
+	}
+	t := (v - s.Min) / (s.Max - s.Min)
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+	segs := len(s.Stops) - 1
+	pos := t * float64(segs)
+	i := int(pos)
+	if i >= segs {
+		i = segs - 1
+	}
+	frac := pos - float64(i)
+	a, b := s.Stops[i], s.Stops[i+1]
+	return color.RGBA{
+		R: lerpByte(a.R, b.R, frac),
+		G: lerpByte(a.G, b.G, frac),
+		B: lerpByte(a.B, b.B, frac),
+		A: 255,
+	}
+}
+
+func lerpByte(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
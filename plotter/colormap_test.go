@@ -0,0 +1,48 @@
+package plotter
+
+import (
+	"image/color"
+	"testing"
+)
+
+// TestColorScaleClampsOutOfRange checks values outside [Min, Max] clamp to
+// the nearest end stop instead of extrapolating.
+func TestColorScaleClampsOutOfRange(t *testing.T) {
+	s := scales[MapREL]
+	below := s.Color(s.Min - 10)
+	atMin := s.Color(s.Min)
+	if below != atMin {
+		t.Errorf("Color(below Min) = %+v, want same as Color(Min) = %+v", below, atMin)
+	}
+	above := s.Color(s.Max + 10)
+	atMax := s.Color(s.Max)
+	if above != atMax {
+		t.Errorf("Color(above Max) = %+v, want same as Color(Max) = %+v", above, atMax)
+	}
+}
+
+// TestColorScaleInterpolatesMidpoint checks the value exactly halfway
+// between the first two stops produces a color halfway between them.
+func TestColorScaleInterpolatesMidpoint(t *testing.T) {
+	s := scales[MapREL]
+	segWidth := (s.Max - s.Min) / float64(len(s.Stops)-1)
+	mid := s.Min + segWidth/2
+
+	got := s.Color(mid)
+	a, b := s.Stops[0], s.Stops[1]
+	want := lerpByte(a.R, b.R, 0.5)
+	if got.R != want {
+		t.Errorf("Color(midpoint).R = %d, want %d (halfway between %d and %d)", got.R, want, a.R, b.R)
+	}
+}
+
+// TestColorScaleSingleStop covers the single-stop edge case, used by
+// nothing in the current scales table but guarded against explicitly by
+// Color's len(s.Stops) == 1 branch.
+func TestColorScaleSingleStop(t *testing.T) {
+	s := colorScale{Min: 0, Max: 10, Stops: []color.RGBA{{10, 20, 30, 255}}}
+	got := s.Color(5)
+	if got != s.Stops[0] {
+		t.Errorf("Color with a single stop = %+v, want %+v", got, s.Stops[0])
+	}
+}
@@ -0,0 +1,107 @@
+package plotter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DeckInfo is the subset of a .voa control deck Render needs: the transmit
+// site to mark on the map, and the Area/Gridsize the matching .vgN grid
+// file was sampled against. Field layout mirrors the fixed-column deck
+// written by run_p2p's makeVOACAPPrediction, e.g.:
+//
+//	Transmit : 60.17     24.94     OH6BG                G3
+//	Area     :    -180.0     180.0     -90.0      90.0
+//	Gridsize :  125    1
+type DeckInfo struct {
+	TxLat, TxLon float64
+	TxName       string
+	Bounds       [4]float64 // lonMin, lonMax, latMin, latMax
+	GridSize     int
+}
+
+// readDeck scans path line by line for the Transmit/Area/Gridsize lines
+// and parses their fixed fields. It tolerates any amount of whitespace
+// around the values, since the deck writer right-aligns some of them.
+func readDeck(path string) (DeckInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return DeckInfo{}, err
+	}
+	defer f.Close()
+
+	var info DeckInfo
+	haveTx, haveArea, haveGrid := false, false, false
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		label, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		label = strings.TrimSpace(label)
+		fields := strings.Fields(rest)
+
+		switch label {
+		case "Transmit":
+			if len(fields) < 3 {
+				continue
+			}
+			lat, errLat := strconv.ParseFloat(fields[0], 64)
+			lon, errLon := strconv.ParseFloat(fields[1], 64)
+			if errLat != nil || errLon != nil {
+				continue
+			}
+			info.TxLat, info.TxLon, info.TxName = lat, lon, fields[2]
+			haveTx = true
+		case "Area":
+			if len(fields) < 4 {
+				continue
+			}
+			var bounds [4]float64
+			bad := false
+			for i := 0; i < 4; i++ {
+				v, err := strconv.ParseFloat(fields[i], 64)
+				if err != nil {
+					bad = true
+					break
+				}
+				bounds[i] = v
+			}
+			if bad {
+				continue
+			}
+			info.Bounds = bounds
+			haveArea = true
+		case "Gridsize":
+			if len(fields) < 1 {
+				continue
+			}
+			n, err := strconv.Atoi(fields[0])
+			if err != nil {
+				continue
+			}
+			info.GridSize = n
+			haveGrid = true
+		}
+		if haveTx && haveArea && haveGrid {
+			break
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return DeckInfo{}, err
+	}
+	if !haveTx {
+		return DeckInfo{}, fmt.Errorf("%s: no Transmit line found", path)
+	}
+	if !haveArea {
+		// Every deck run_p2p writes today covers the whole globe; fall back
+		// to that rather than failing a native plot over a missing Area line.
+		info.Bounds = [4]float64{-180, 180, -90, 90}
+	}
+	return info, nil
+}
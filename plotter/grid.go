@@ -0,0 +1,109 @@
+package plotter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Grid is a square numeric sample grid read from a .vgN file, laid out
+// row-major north-to-south, west-to-east over Bounds. This is the same
+// convention run_p2p's AreaGrid uses for GeoTIFF/GeoJSON output, since a
+// .vgN file is voacapl's area/calc output copied alongside the .voa deck.
+type Grid struct {
+	LonMin, LonMax, LatMin, LatMax float64
+	N                              int
+	Values                         [][]float64 // [latIdx][lonIdx]
+}
+
+// readGrid reads gridSize*gridSize whitespace-separated numeric samples
+// out of path, skipping any non-numeric header/label tokens. When
+// gridSize is less than 2 (deck didn't yield one), it is inferred as the
+// integer square root of the numeric sample count instead of failing.
+func readGrid(path string, bounds [4]float64, gridSize int) (*Grid, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(data))
+	vals := make([]float64, 0, len(fields))
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			continue
+		}
+		vals = append(vals, v)
+	}
+
+	n := gridSize
+	if n < 2 {
+		n = int(math.Round(math.Sqrt(float64(len(vals)))))
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("%s: only %d numeric samples found, cannot infer a grid", path, len(vals))
+	}
+	want := n * n
+	if len(vals) < want {
+		return nil, fmt.Errorf("%s: found %d numeric samples, want %d (%dx%d grid)", path, len(vals), want, n, n)
+	}
+	vals = vals[:want]
+
+	g := &Grid{LonMin: bounds[0], LonMax: bounds[1], LatMin: bounds[2], LatMax: bounds[3], N: n}
+	g.Values = make([][]float64, n)
+	for i := 0; i < n; i++ {
+		g.Values[i] = vals[i*n : (i+1)*n]
+	}
+	return g, nil
+}
+
+func (g *Grid) lonStep() float64 { return (g.LonMax - g.LonMin) / float64(g.N) }
+func (g *Grid) latStep() float64 { return (g.LatMax - g.LatMin) / float64(g.N-1) }
+
+// valueAt bilinearly samples the grid at an arbitrary (lat, lon), clamping
+// to the nearest edge cell outside the sampled range.
+func (g *Grid) valueAt(lat, lon float64) float64 {
+	lon = normalizeLon(lon, g.LonMin)
+	fj := (lon - g.LonMin) / g.lonStep()
+	fi := (g.LatMax - lat) / g.latStep()
+	fi = math.Max(0, math.Min(fi, float64(g.N-1)))
+
+	j0 := clampInt(int(math.Floor(fj)), 0, g.N-1)
+	i0 := clampInt(int(math.Floor(fi)), 0, g.N-1)
+	j1 := (j0 + 1) % g.N
+	i1 := clampInt(i0+1, 0, g.N-1)
+
+	tj := fj - float64(j0)
+	ti := fi - float64(i0)
+
+	v00 := g.Values[i0][j0]
+	v01 := g.Values[i0][j1]
+	v10 := g.Values[i1][j0]
+	v11 := g.Values[i1][j1]
+
+	top := v00 + (v01-v00)*tj
+	bot := v10 + (v11-v10)*tj
+	return top + (bot-top)*ti
+}
+
+func normalizeLon(lon, lonMin float64) float64 {
+	span := 360.0
+	for lon < lonMin {
+		lon += span
+	}
+	for lon >= lonMin+span {
+		lon -= span
+	}
+	return lon
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
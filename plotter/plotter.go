@@ -0,0 +1,122 @@
+// Package plotter renders a VOACAP area prediction (a .voa control deck
+// plus its matching .vgN grid output) directly to a PNG coverage map,
+// without shelling out to a Python/matplotlib plotting script.
+package plotter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+const (
+	defaultWidth  = 1024
+	defaultHeight = 512
+)
+
+var (
+	coastlineColor = color.RGBA{200, 200, 200, 255}
+	markerColor    = color.RGBA{255, 255, 255, 255}
+)
+
+// Options configures a single Render call.
+type Options struct {
+	VOAPath string  // the .voa control deck (for TX site, Area, Gridsize)
+	VGPath  string  // the .vgN grid file matching VOAPath for this frequency/hour
+	MapType MapType // which parameter/color scale to render
+
+	OutFile string // PNG destination; written atomically via a .tmp + rename
+
+	Width, Height int        // canvas size; default 1024x512 if either is 0
+	Projection    Projection // default ProjMiller if empty
+}
+
+// Render reads opts.VOAPath and opts.VGPath and writes a colorized PNG
+// coverage map to opts.OutFile. It is safe to call concurrently from
+// multiple goroutines: Render holds no package-level mutable state beyond
+// the read-only, once-initialized coastline cache. ctx is polled once per
+// scanline during the pixel walk (the dominant cost for large canvases),
+// so a caller enforcing a per-render deadline via context.WithTimeout gets
+// a real cancellation point instead of only a post-hoc "it ran long" check.
+func Render(ctx context.Context, opts Options) error {
+	scale, ok := scales[opts.MapType]
+	if !ok {
+		return fmt.Errorf("plotter: unknown map type %q", opts.MapType)
+	}
+
+	deck, err := readDeck(opts.VOAPath)
+	if err != nil {
+		return fmt.Errorf("plotter: reading deck %s: %w", opts.VOAPath, err)
+	}
+	grid, err := readGrid(opts.VGPath, deck.Bounds, deck.GridSize)
+	if err != nil {
+		return fmt.Errorf("plotter: reading grid %s: %w", opts.VGPath, err)
+	}
+
+	width, height := opts.Width, opts.Height
+	if width == 0 || height == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	proj := opts.Projection
+	if proj == "" {
+		proj = ProjMiller
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for x := 0; x < width; x++ {
+			lat, lon, ok := invProject(proj, x, y, grid.LonMin, grid.LonMax, deck.TxLat, deck.TxLon, width, height)
+			if !ok {
+				continue // outside the projection's drawable area (e.g. past the azimuthal antipode)
+			}
+			v := grid.valueAt(lat, lon)
+			img.SetRGBA(x, y, scale.Color(v))
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	drawCoastlines(img, proj, grid.LonMin, grid.LonMax, deck.TxLat, deck.TxLon, width, height, coastlineColor)
+	drawMarker(img, proj, deck.TxLat, deck.TxLon, grid.LonMin, grid.LonMax, deck.TxLat, deck.TxLon, width, height, markerColor, 3)
+
+	return writePNG(opts.OutFile, img)
+}
+
+// writePNG encodes img and writes it to path via a temp file + rename, the
+// same atomic-write convention run_p2p uses for its deck and checkpoint
+// files, so a crashed/interrupted render never leaves a partial PNG behind.
+func writePNG(path string, img image.Image) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if err := png.Encode(bw, img); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
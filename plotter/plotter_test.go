@@ -0,0 +1,75 @@
+package plotter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFixtureDeckAndGrid(t *testing.T) (voaPath, vgPath string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	voaPath = filepath.Join(dir, "cap_014.100.voa")
+	deck := "Transmit :  45.00    10.00   TEST                 G3\n" +
+		"Area     :    -180.0     180.0     -90.0      90.0\n" +
+		"Gridsize :    2    1\n"
+	if err := os.WriteFile(voaPath, []byte(deck), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	vgPath = filepath.Join(dir, "cap_014.100.vg1")
+	if err := os.WriteFile(vgPath, []byte("1.0 2.0\n3.0 4.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return voaPath, vgPath
+}
+
+// TestRenderHonorsCanceledContext pins that Render actually polls ctx
+// instead of only checking it after a synchronous pixel walk completes:
+// a context canceled before the call returns ctx.Err() without writing
+// OutFile.
+func TestRenderHonorsCanceledContext(t *testing.T) {
+	voaPath, vgPath := writeFixtureDeckAndGrid(t)
+	outFile := filepath.Join(t.TempDir(), "out.png")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Render(ctx, Options{
+		VOAPath: voaPath,
+		VGPath:  vgPath,
+		MapType: MapREL,
+		OutFile: outFile,
+		Width:   16,
+		Height:  16,
+	})
+	if err != context.Canceled {
+		t.Fatalf("Render with a pre-canceled context: got err=%v, want context.Canceled", err)
+	}
+	if _, statErr := os.Stat(outFile); statErr == nil {
+		t.Errorf("Render wrote %s despite a canceled context", outFile)
+	}
+}
+
+// TestRenderSucceedsWithLiveContext is the control case: a normal,
+// uncancelled context still renders a PNG end to end.
+func TestRenderSucceedsWithLiveContext(t *testing.T) {
+	voaPath, vgPath := writeFixtureDeckAndGrid(t)
+	outFile := filepath.Join(t.TempDir(), "out.png")
+
+	if err := Render(context.Background(), Options{
+		VOAPath: voaPath,
+		VGPath:  vgPath,
+		MapType: MapREL,
+		OutFile: outFile,
+		Width:   16,
+		Height:  16,
+	}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if _, err := os.Stat(outFile); err != nil {
+		t.Errorf("Render did not write %s: %v", outFile, err)
+	}
+}
@@ -0,0 +1,144 @@
+package plotter
+
+import "math"
+
+// Projection selects how (lat, lon) degrees are mapped onto the canvas.
+type Projection string
+
+const (
+	// ProjMiller is Miller cylindrical, a reasonable general-purpose
+	// default for a global coverage map (unlike Mercator it stays finite
+	// at the poles). This is the default when Options.Projection is "".
+	ProjMiller Projection = "miller"
+	// ProjMercator is standard Mercator, clamped to ±85 deg latitude.
+	ProjMercator Projection = "mercator"
+	// ProjAzimuthal is an azimuthal equidistant projection centered on
+	// the transmit site, useful for judging great-circle distance/bearing
+	// from a single-TX area prediction at a glance.
+	ProjAzimuthal Projection = "azimuthal"
+)
+
+const millerYMax = 2.3034 // 1.25*ln(tan(pi/4 + 0.4*90deg)), the y extent at the poles
+const mercatorClampDeg = 85.0
+
+// project maps a (lat, lon) in degrees onto a pixel (x, y) in a
+// width x height canvas. lonMin/lonMax bound the cylindrical projections;
+// centerLat/centerLon is the azimuthal projection's center. ok is true for
+// every input: Mercator clamps latitudes past ±85 deg instead of rejecting
+// them, and every point on the globe, the antipode included, has an
+// azimuthal position. (invProject is the one that reports ok=false, for
+// pixels outside the azimuthal disc.)
+func project(proj Projection, lat, lon float64, lonMin, lonMax, centerLat, centerLon float64, width, height int) (x, y int, ok bool) {
+	switch proj {
+	case ProjMercator:
+		if lat > mercatorClampDeg {
+			lat = mercatorClampDeg
+		}
+		if lat < -mercatorClampDeg {
+			lat = -mercatorClampDeg
+		}
+		yMax := math.Log(math.Tan(math.Pi/4 + radians(mercatorClampDeg)/2))
+		yv := math.Log(math.Tan(math.Pi/4 + radians(lat)/2))
+		return cylindricalXY(lon, yv, yMax, lonMin, lonMax, width, height)
+	case ProjAzimuthal:
+		return azimuthalXY(lat, lon, centerLat, centerLon, width, height)
+	default: // ProjMiller
+		yv := 1.25 * math.Log(math.Tan(math.Pi/4+0.4*radians(lat)))
+		return cylindricalXY(lon, yv, millerYMax, lonMin, lonMax, width, height)
+	}
+}
+
+func cylindricalXY(lon, yv, yMax, lonMin, lonMax float64, width, height int) (x, y int, ok bool) {
+	lon = normalizeLon(lon, lonMin)
+	fx := (lon - lonMin) / (lonMax - lonMin)
+	fy := (yMax - yv) / (2 * yMax) // yv in [-yMax, yMax] -> fy in [0, 1], north at top
+	return int(fx * float64(width)), int(fy * float64(height)), true
+}
+
+func azimuthalXY(lat, lon, centerLat, centerLon float64, width, height int) (x, y int, ok bool) {
+	phi1, phi2 := radians(centerLat), radians(lat)
+	dLambda := radians(lon - centerLon)
+
+	cosC := math.Sin(phi1)*math.Sin(phi2) + math.Cos(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	if cosC > 1 {
+		cosC = 1
+	}
+	if cosC < -1 {
+		cosC = -1
+	}
+	c := math.Acos(cosC)
+
+	bx := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	by := math.Cos(phi2) * math.Sin(dLambda)
+	theta := math.Atan2(by, bx)
+
+	// Scale so the antipode (c == pi) lands on the inscribed circle of the
+	// canvas; points past it (impossible, c is at most pi) would be clipped.
+	radius := 0.5 * math.Min(float64(width), float64(height))
+	r := (c / math.Pi) * radius
+	cx := float64(width)/2 + r*math.Sin(theta)
+	cy := float64(height)/2 - r*math.Cos(theta)
+	return int(cx), int(cy), true
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// invProject is project's inverse: given a pixel, recover the (lat, lon)
+// it represents, so Render can walk the canvas pixel-by-pixel and sample
+// the grid at the exact point each pixel covers (rather than forward-
+// filling coarse grid cells, which would leave gaps once the canvas has
+// more pixels than the grid has samples).
+func invProject(proj Projection, x, y int, lonMin, lonMax, centerLat, centerLon float64, width, height int) (lat, lon float64, ok bool) {
+	switch proj {
+	case ProjMercator:
+		yMax := math.Log(math.Tan(math.Pi/4 + radians(mercatorClampDeg)/2))
+		lon, lat = invCylindrical(x, y, yMax, lonMin, lonMax, width, height)
+		return lat, lon, true
+	case ProjAzimuthal:
+		return invAzimuthal(x, y, centerLat, centerLon, width, height)
+	default: // ProjMiller
+		yMax := millerYMax
+		lon, yv := invCylindricalRaw(x, y, yMax, lonMin, lonMax, width, height)
+		latRad := (math.Atan(math.Exp(yv/1.25)) - math.Pi/4) / 0.4
+		return degrees(latRad), lon, true
+	}
+}
+
+// invCylindricalRaw recovers (lon, yv) — the intermediate y value the
+// forward cylindricalXY used — from a pixel position.
+func invCylindricalRaw(x, y int, yMax, lonMin, lonMax float64, width, height int) (lon, yv float64) {
+	fx := float64(x) / float64(width)
+	fy := float64(y) / float64(height)
+	lon = lonMin + fx*(lonMax-lonMin)
+	yv = yMax - fy*2*yMax
+	return lon, yv
+}
+
+// invCylindrical is the Mercator specialization: it returns (lon, lat)
+// directly by inverting the Mercator y formula on the recovered yv.
+func invCylindrical(x, y int, yMax, lonMin, lonMax float64, width, height int) (lon, lat float64) {
+	lon, yv := invCylindricalRaw(x, y, yMax, lonMin, lonMax, width, height)
+	latRad := 2 * (math.Atan(math.Exp(yv)) - math.Pi/4)
+	return lon, degrees(latRad)
+}
+
+func invAzimuthal(x, y int, centerLat, centerLon float64, width, height int) (lat, lon float64, ok bool) {
+	dx := float64(x) - float64(width)/2
+	dy := float64(y) - float64(height)/2
+	r := math.Hypot(dx, dy)
+	radius := 0.5 * math.Min(float64(width), float64(height))
+	c := (r / radius) * math.Pi
+	if c > math.Pi {
+		return 0, 0, false
+	}
+	theta := math.Atan2(dx, -dy)
+
+	phi1 := radians(centerLat)
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(c) + math.Cos(phi1)*math.Sin(c)*math.Cos(theta))
+	lambda2 := radians(centerLon) + math.Atan2(
+		math.Sin(theta)*math.Sin(c)*math.Cos(phi1),
+		math.Cos(c)-math.Sin(phi1)*math.Sin(phi2),
+	)
+	return degrees(phi2), degrees(lambda2), true
+}
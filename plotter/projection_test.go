@@ -0,0 +1,74 @@
+package plotter
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(a, b, tol float64) bool { return math.Abs(a-b) <= tol }
+
+// TestProjectInvProjectRoundTrip checks that projecting a (lat, lon) to a
+// pixel and inverting it back recovers approximately the same point, for
+// each projection Render supports. Tolerance accounts for the integer
+// pixel truncation both project and invProject go through.
+func TestProjectInvProjectRoundTrip(t *testing.T) {
+	const width, height = 720, 360
+	const lonMin, lonMax = -180.0, 180.0
+	const centerLat, centerLon = 40.0, -75.0
+
+	cases := []struct {
+		proj     Projection
+		lat, lon float64
+	}{
+		{ProjMiller, 30, 45},
+		{ProjMiller, -60, -120},
+		{ProjMercator, 10, 0},
+		{ProjMercator, -45, 170},
+		{ProjAzimuthal, 35, -70},
+	}
+	for _, c := range cases {
+		x, y, ok := project(c.proj, c.lat, c.lon, lonMin, lonMax, centerLat, centerLon, width, height)
+		if !ok {
+			t.Fatalf("project(%s, %v, %v): ok=false", c.proj, c.lat, c.lon)
+		}
+		lat2, lon2, ok := invProject(c.proj, x, y, lonMin, lonMax, centerLat, centerLon, width, height)
+		if !ok {
+			t.Fatalf("invProject(%s, %d, %d): ok=false", c.proj, x, y)
+		}
+		if !approxEqual(lat2, c.lat, 1.0) {
+			t.Errorf("%s: round-tripped lat = %v, want ~%v", c.proj, lat2, c.lat)
+		}
+		if !approxEqual(lon2, c.lon, 1.0) {
+			t.Errorf("%s: round-tripped lon = %v, want ~%v", c.proj, lon2, c.lon)
+		}
+	}
+}
+
+// TestInvAzimuthalPastAntipode checks invProject reports ok=false once
+// the pixel radius exceeds the canvas's inscribed circle (c > pi), the
+// only "no usable position" case the azimuthal projection has.
+func TestInvAzimuthalPastAntipode(t *testing.T) {
+	const width, height = 400, 400
+	// A corner pixel is outside the inscribed circle of radius
+	// 0.5*min(width,height), so its implied c exceeds pi.
+	_, _, ok := invProject(ProjAzimuthal, 0, 0, -180, 180, 40, -75, width, height)
+	if ok {
+		t.Error("invAzimuthal at a corner pixel (outside the inscribed circle): want ok=false")
+	}
+}
+
+// TestMercatorClampsHighLatitudes checks latitudes past the ±85 deg clamp
+// still produce a finite, in-bounds pixel instead of diverging to ±inf.
+func TestMercatorClampsHighLatitudes(t *testing.T) {
+	const width, height = 720, 360
+	x, y, ok := project(ProjMercator, 89, 0, -180, 180, 0, 0, width, height)
+	if !ok {
+		t.Fatal("project(Mercator, 89, 0): ok=false")
+	}
+	if y < 0 || y >= height {
+		t.Errorf("y = %d out of [0, %d) for a near-pole latitude", y, height)
+	}
+	if x < 0 || x >= width {
+		t.Errorf("x = %d out of [0, %d)", x, width)
+	}
+}
@@ -0,0 +1,376 @@
+// Package voafile discovers and parses VOACAP run output: a directory tree
+// of .voa control decks, each with one or more sibling .vgN grid files (one
+// per hour/frequency the deck was run for). Scan walks a ROOT directory and
+// returns a typed catalog, so plot_maps and any other consumer (a native
+// plotter, a dashboard, a catalog indexer) share one parser instead of each
+// re-implementing the fragile regex/heuristic fallbacks VG files sometimes
+// require.
+package voafile
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VOASet is one .voa control deck and the .vgN grid files found alongside
+// it, with Year/Month derived from its position under the scanned root.
+type VOASet struct {
+	Path  string // the .voa control deck
+	Year  string
+	Month string
+	VGs   []VGFile
+}
+
+// VGFile is one .vgN grid file belonging to a VOASet's deck.
+type VGFile struct {
+	Path    string
+	Index   int // the N in .vgN
+	Hour    int // UTC hour, 0-23
+	FreqMHz float64
+
+	bounds   [4]float64 // lonMin, lonMax, latMin, latMax, from the deck's Area line
+	gridSize int        // from the deck's Gridsize line; inferred from sample count if 0
+}
+
+var (
+	reVgNum  = regexp.MustCompile(`(?i)\.vg(\d+)$`)
+	reUtHour = regexp.MustCompile(`(?i)\b([01]?\d|2[0-4])\s*(?:UT|UTC|Z)\b`)
+	reMHz    = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*MHz\b`)
+	reFreq   = regexp.MustCompile(`(?i)\bF(?:REQ)?\s*[=:]\s*(\d+(?:\.\d+)?)\b`)
+)
+
+// Scan walks root and returns one VOASet per directory, built from that
+// directory's newest .voa file and its sibling .vgN files. Only the newest
+// .voa per directory is considered, matching the assumption the rest of the
+// toolchain already makes: a directory holds at most one live run at a time,
+// and an older .voa left behind by a previous run should be ignored.
+func Scan(root string) ([]VOASet, error) {
+	voaFiles, err := newestVoaPerDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]VOASet, 0, len(voaFiles))
+	for _, voa := range voaFiles {
+		vgPaths, err := listSiblingVG(voa)
+		if err != nil {
+			return nil, fmt.Errorf("voafile: listing VG files for %s: %w", voa, err)
+		}
+		year, month := yearMonthFrom(filepath.Dir(voa), root)
+
+		bounds, gridSize := readDeckGeometry(voa)
+
+		vgs := make([]VGFile, 0, len(vgPaths))
+		for _, vg := range vgPaths {
+			vgNum := vgNumber(vg)
+			if vgNum == "" {
+				fmt.Fprintf(os.Stderr, "voafile: warn: %s has no numeric .vgN suffix, skipping\n", vg)
+				continue
+			}
+			idx, _ := strconv.Atoi(vgNum)
+			hh, ff := extractHourAndMHz(vg)
+			freq, _ := strconv.ParseFloat(ff, 64)
+			vgs = append(vgs, VGFile{
+				Path:     vg,
+				Index:    idx,
+				Hour:     hh,
+				FreqMHz:  freq,
+				bounds:   bounds,
+				gridSize: gridSize,
+			})
+		}
+		sets = append(sets, VOASet{Path: voa, Year: year, Month: month, VGs: vgs})
+	}
+	return sets, nil
+}
+
+// Grid reads and parses v's numeric grid file into a gridSize x gridSize
+// row-major (north-to-south, west-to-east) array, using the bounds and grid
+// size recorded in its deck's Area/Gridsize lines. Scan itself never reads
+// grid data, since a catalog walk over many thousands of VG files usually
+// only needs the header fields (Hour, FreqMHz) most callers want.
+func (v VGFile) Grid() (bounds [4]float64, values [][]float64, err error) {
+	data, err := os.ReadFile(v.Path)
+	if err != nil {
+		return v.bounds, nil, err
+	}
+	fields := strings.Fields(string(data))
+	vals := make([]float64, 0, len(fields))
+	for _, f := range fields {
+		val, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			continue
+		}
+		vals = append(vals, val)
+	}
+
+	n := v.gridSize
+	if n < 2 {
+		n = int(math.Round(math.Sqrt(float64(len(vals)))))
+	}
+	if n < 2 {
+		return v.bounds, nil, fmt.Errorf("%s: only %d numeric samples found, cannot infer a grid", v.Path, len(vals))
+	}
+	want := n * n
+	if len(vals) < want {
+		return v.bounds, nil, fmt.Errorf("%s: found %d numeric samples, want %d (%dx%d grid)", v.Path, len(vals), want, n, n)
+	}
+	vals = vals[:want]
+
+	grid := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		grid[i] = vals[i*n : (i+1)*n]
+	}
+	return v.bounds, grid, nil
+}
+
+// readDeckGeometry scans a .voa deck for its Area/Gridsize lines. Unlike
+// plotter's readDeck it doesn't require a Transmit line or return an error:
+// a VOASet should still be usable for cataloging even if its deck's grid
+// geometry can't be determined, since Grid() already falls back to
+// inferring the dimension from the sample count.
+func readDeckGeometry(path string) (bounds [4]float64, gridSize int) {
+	bounds = [4]float64{-180, 180, -90, 90}
+	f, err := os.Open(path)
+	if err != nil {
+		return bounds, 0
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		label, rest, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		label = strings.TrimSpace(label)
+		fields := strings.Fields(rest)
+
+		switch label {
+		case "Area":
+			if len(fields) < 4 {
+				continue
+			}
+			var b [4]float64
+			bad := false
+			for i := 0; i < 4; i++ {
+				v, err := strconv.ParseFloat(fields[i], 64)
+				if err != nil {
+					bad = true
+					break
+				}
+				b[i] = v
+			}
+			if !bad {
+				bounds = b
+			}
+		case "Gridsize":
+			if len(fields) < 1 {
+				continue
+			}
+			if n, err := strconv.Atoi(fields[0]); err == nil {
+				gridSize = n
+			}
+		}
+	}
+	return bounds, gridSize
+}
+
+func newestVoaPerDir(root string) ([]string, error) {
+	dirNewest := map[string]string{}
+	dirMtime := map[string]fs.FileInfo{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".voa") {
+			return nil
+		}
+		info, e := d.Info()
+		if e != nil {
+			return e
+		}
+		dir := filepath.Dir(path)
+		prev, ok := dirNewest[dir]
+		if !ok || info.ModTime().After(dirMtime[dir].ModTime()) ||
+			(info.ModTime().Equal(dirMtime[dir].ModTime()) && path > prev) {
+			dirNewest[dir] = path
+			dirMtime[dir] = info
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(dirNewest))
+	for _, p := range dirNewest {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func listSiblingVG(voa string) ([]string, error) {
+	dir := filepath.Dir(voa)
+	base := strings.TrimSuffix(filepath.Base(voa), filepath.Ext(voa))
+	wantPrefix := strings.ToLower(base) + ".vg"
+
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range ents {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if strings.HasPrefix(strings.ToLower(name), wantPrefix) {
+			out = append(out, filepath.Join(dir, name))
+		}
+	}
+	// Sort by numeric suffix (vg index) if present, else by name
+	sort.Slice(out, func(i, j int) bool {
+		ni, _ := strconv.Atoi(vgNumber(out[i]))
+		nj, _ := strconv.Atoi(vgNumber(out[j]))
+		if ni != 0 && nj != 0 {
+			return ni < nj
+		}
+		return out[i] < out[j]
+	})
+	return out, nil
+}
+
+func vgNumber(vg string) string {
+	m := reVgNum.FindStringSubmatch(strings.ToLower(vg))
+	if len(m) == 2 {
+		return m[1]
+	}
+	// fallback: last digits of extension
+	ext := strings.ToLower(filepath.Ext(vg))
+	for i := len(ext) - 1; i >= 0; i-- {
+		if ext[i] < '0' || ext[i] > '9' {
+			return ext[i+1:]
+		}
+	}
+	return ""
+}
+
+// extractHourAndMHz scans up to 50 lines of vg for a UT-hour and MHz
+// frequency via regex, falling back to a positional heuristic on the
+// second line when the regexes don't match. freqCode is the 2-digit,
+// zero-padded integer MHz used in the VG/PNG filename convention (e.g.
+// "14"), not a precise frequency — the original heuristic only ever
+// recovers the integer part.
+func extractHourAndMHz(vg string) (hour int, freqCode string) {
+	f, err := os.Open(vg)
+	if err != nil {
+		return 0, "00"
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	buf := make([]byte, 0, 64*1024)
+	sc.Buffer(buf, 1<<20)
+
+	h := -1
+	freq := -1
+	second := ""
+	lineIdx := 0
+
+	for sc.Scan() {
+		line := sc.Text()
+		lineIdx++
+		if lineIdx == 2 {
+			second = line
+		}
+		if h < 0 {
+			if m := reUtHour.FindStringSubmatch(line); len(m) == 2 {
+				if v, err := strconv.Atoi(m[1]); err == nil {
+					h = ((v % 24) + 24) % 24
+				}
+			}
+		}
+		if freq < 0 {
+			if m := reMHz.FindStringSubmatch(line); len(m) == 2 {
+				if v, err := strconv.Atoi(m[1]); err == nil {
+					freq = clamp(v, 0, 99)
+				}
+			} else if m := reFreq.FindStringSubmatch(line); len(m) == 2 {
+				if v, err := strconv.Atoi(m[1]); err == nil {
+					freq = clamp(v, 0, 99)
+				}
+			}
+		}
+		if h >= 0 && freq >= 0 {
+			break
+		}
+		if lineIdx >= 50 { // don't scan entire file
+			break
+		}
+	}
+
+	if h < 0 && second != "" {
+		toks := strings.Fields(second)
+		if len(toks) >= 4 {
+			if v, err := strconv.Atoi(toks[len(toks)-4]); err == nil {
+				h = ((v % 24) + 24) % 24
+			}
+		}
+	}
+	if freq < 0 && second != "" {
+		toks := strings.Fields(second)
+		for i := len(toks) - 1; i >= 0; i-- {
+			t := strings.TrimSuffix(strings.ToUpper(toks[i]), "MHZ")
+			if v, err := strconv.Atoi(t); err == nil {
+				freq = clamp(v, 0, 99)
+				break
+			}
+		}
+	}
+
+	if h < 0 {
+		h = 0
+	}
+	if freq < 0 {
+		freq = 0
+	}
+	return h, fmt.Sprintf("%02d", freq)
+}
+
+func yearMonthFrom(voaDir, root string) (year, month string) {
+	rel, err := filepath.Rel(root, voaDir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "Unknown", "Unknown"
+	}
+	parts := strings.Split(rel, string(os.PathSeparator))
+	switch len(parts) {
+	case 0:
+		return "Unknown", "Unknown"
+	case 1:
+		return parts[0], "Unknown"
+	default:
+		return parts[0], parts[1]
+	}
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+package voafile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestScan builds a minimal ROOT/<year>/<month>/ tree with one .voa deck
+// and two sibling .vgN files and checks Scan recovers the year/month,
+// vg index, hour, and frequency for each.
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "2026", "Jul")
+	voaPath := filepath.Join(dir, "cap_014.100.voa")
+	writeFile(t, voaPath, "Transmit : 45.00   10.00   TEST                 G3\n"+
+		"Area     :    -180.0     180.0     -90.0      90.0\n"+
+		"Gridsize :    2    1\n")
+	writeFile(t, filepath.Join(dir, "cap_014.100.vg1"), "12 UT   14 MHz\n1.0 2.0 3.0 4.0\n")
+	writeFile(t, filepath.Join(dir, "cap_014.100.vg2"), "13 UT   14 MHz\n1.0 2.0 3.0 4.0\n")
+
+	sets, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(sets) != 1 {
+		t.Fatalf("got %d VOASets, want 1", len(sets))
+	}
+	s := sets[0]
+	if s.Year != "2026" || s.Month != "Jul" {
+		t.Errorf("got Year/Month %q/%q, want 2026/Jul", s.Year, s.Month)
+	}
+	if len(s.VGs) != 2 {
+		t.Fatalf("got %d VGs, want 2", len(s.VGs))
+	}
+	for _, vg := range s.VGs {
+		if vg.FreqMHz != 14 {
+			t.Errorf("vg%d: got FreqMHz=%v, want 14", vg.Index, vg.FreqMHz)
+		}
+	}
+	if s.VGs[0].Index != 1 || s.VGs[0].Hour != 12 {
+		t.Errorf("vg1: got Index=%d Hour=%d, want 1/12", s.VGs[0].Index, s.VGs[0].Hour)
+	}
+	if s.VGs[1].Index != 2 || s.VGs[1].Hour != 13 {
+		t.Errorf("vg2: got Index=%d Hour=%d, want 2/13", s.VGs[1].Index, s.VGs[1].Hour)
+	}
+}
+
+// TestScanNewestVoaWins covers the "only the newest .voa per directory"
+// rule: an older .voa left behind by a previous run must be ignored.
+func TestScanNewestVoaWins(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "2026", "Jul")
+	oldPath := filepath.Join(dir, "cap_007.000.voa")
+	newPath := filepath.Join(dir, "cap_014.100.voa")
+	writeFile(t, oldPath, "Transmit : 45.00   10.00   TEST                 G3\n")
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(oldPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, newPath, "Transmit : 45.00   10.00   TEST                 G3\n")
+	writeFile(t, filepath.Join(dir, "cap_014.100.vg1"), "00 UT   14 MHz\n1.0 2.0 3.0 4.0\n")
+
+	sets, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(sets) != 1 {
+		t.Fatalf("got %d VOASets, want 1", len(sets))
+	}
+	if sets[0].Path != newPath {
+		t.Errorf("got Path=%s, want the newest .voa %s", sets[0].Path, newPath)
+	}
+}
+
+// TestVGFileGrid covers the lazy Grid() reader against the Gridsize/Area
+// recorded in the deck.
+func TestVGFileGrid(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "2026", "Jul")
+	writeFile(t, filepath.Join(dir, "cap_014.100.voa"), "Transmit : 45.00   10.00   TEST                 G3\n"+
+		"Area     :    -180.0     180.0     -90.0      90.0\n"+
+		"Gridsize :    2    1\n")
+	// Grid() parses every numeric token in the file (it doesn't separately
+	// skip a header line the way extractHourAndMHz's regexes do), so this
+	// fixture carries no non-grid numeric tokens.
+	writeFile(t, filepath.Join(dir, "cap_014.100.vg1"), "1.0 2.0 3.0 4.0\n")
+
+	sets, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	bounds, grid, err := sets[0].VGs[0].Grid()
+	if err != nil {
+		t.Fatalf("Grid: %v", err)
+	}
+	if bounds != [4]float64{-180, 180, -90, 90} {
+		t.Errorf("got bounds %v, want full globe", bounds)
+	}
+	if len(grid) != 2 || len(grid[0]) != 2 {
+		t.Fatalf("got %dx%d grid, want 2x2", len(grid), len(grid[0]))
+	}
+	if grid[0][0] != 1 || grid[0][1] != 2 || grid[1][0] != 3 || grid[1][1] != 4 {
+		t.Errorf("got grid %v, want [[1 2] [3 4]]", grid)
+	}
+}
+
+// TestScanSkipsNonNumericVGSibling covers a stray file (e.g. an editor
+// swap file or a ".vgbak" backup) sitting next to a real .vgN: it matches
+// listSiblingVG's prefix match but has no numeric suffix, and must be
+// skipped rather than fed into the catalog as a bogus Index: 0 entry.
+func TestScanSkipsNonNumericVGSibling(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "2026", "Jul")
+	writeFile(t, filepath.Join(dir, "cap_014.100.voa"), "Transmit : 45.00   10.00   TEST                 G3\n"+
+		"Area     :    -180.0     180.0     -90.0      90.0\n"+
+		"Gridsize :    2    1\n")
+	writeFile(t, filepath.Join(dir, "cap_014.100.vg1"), "12 UT   14 MHz\n1.0 2.0 3.0 4.0\n")
+	writeFile(t, filepath.Join(dir, "cap_014.100.vgbak"), "stray backup file\n")
+
+	sets, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(sets[0].VGs) != 1 {
+		t.Fatalf("got %d VGs, want 1 (the .vgbak sibling should be skipped)", len(sets[0].VGs))
+	}
+	if sets[0].VGs[0].Index != 1 {
+		t.Errorf("got Index=%d, want 1", sets[0].VGs[0].Index)
+	}
+}
+
+// TestExtractHourAndMHzFallback covers the positional-heuristic fallback
+// used when a .vgN file's second line has no UT/MHz text the regexes
+// recognize.
+func TestExtractHourAndMHzFallback(t *testing.T) {
+	vg := filepath.Join(t.TempDir(), "cap.vg3")
+	// No "UT"/"MHz" token; second line's 4th-from-last field is the hour,
+	// and the last bare integer token is the MHz, per the heuristic
+	// fallback extractHourAndMHz uses when the regexes find nothing.
+	writeFile(t, vg, "header\nfoo 09 baz qux 21\n")
+
+	hour, freqCode := extractHourAndMHz(vg)
+	if hour != 9 {
+		t.Errorf("got hour=%d, want 9", hour)
+	}
+	if freqCode != "21" {
+		t.Errorf("got freqCode=%q, want \"21\"", freqCode)
+	}
+}